@@ -102,13 +102,18 @@ func NewApigeeClient(o *ApigeeClientOptions) (*ApigeeClient, error) {
 	baseURL.Path = path.Join(baseURL.Path, "v1/o/", o.Org, "/")
 
 	c := &ApigeeClient{client: httpClient, BaseURL: baseURL, UserAgent: userAgent}
+	c.Apps = &AppsServiceOp{client: c}
+	c.Attributes = &AttributesServiceOp{client: c}
 	c.Caches = &CachesServiceOp{client: c}
 	c.Companies = &CompaniesServiceOp{client: c}
 	c.CompanyAppCredentials = &CompanyAppCredentialsServiceOp{client: c}
 	c.CompanyApps = &CompanyAppsServiceOp{client: c}
+	c.CompanyDevelopers = &CompanyDevelopersServiceOp{client: c}
+	c.DeveloperAppCredentials = &DeveloperAppCredentialsServiceOp{client: c}
 	c.DeveloperApps = &DeveloperAppsServiceOp{client: c}
 	c.Developers = &DevelopersServiceOp{client: c}
 	c.Environments = &EnvironmentsServiceOp{client: c}
+	c.FlowHooks = &FlowHooksServiceOp{client: c}
 	c.KeyValueMapEntries = &KeyValueMapEntriesServiceOp{client: c}
 	c.KeyValueMaps = &KeyValueMapsServiceOp{client: c}
 	c.Options = *o
@@ -0,0 +1,119 @@
+package apigee
+
+import (
+	"fmt"
+)
+
+// AppOwnership is the result of looking up an app. Exactly one of Developer
+// and Company is set, along with the matching DeveloperApp or CompanyApp.
+// Credential is set when the app was found by consumer key.
+type AppOwnership struct {
+	Developer    *Developer
+	DeveloperApp *DeveloperApp
+	Company      *Company
+	CompanyApp   *CompanyApp
+	Credential   *Credential
+}
+
+// Owner returns the email of the developer, or the name of the company, that
+// owns the app.
+func (o *AppOwnership) Owner() string {
+	if o.Company != nil {
+		return o.Company.Name
+	}
+	return o.Developer.Email
+}
+
+// AppName returns the name of the app.
+func (o *AppOwnership) AppName() string {
+	if o.CompanyApp != nil {
+		return o.CompanyApp.Name
+	}
+	return o.DeveloperApp.Name
+}
+
+// Lookup finds an app by its ID, and the developer or company that owns it.
+func (s *AppsServiceOp) Lookup(appId string) (*AppOwnership, *Response, error) {
+	app, resp, e := s.Get(appId)
+	if e != nil {
+		return nil, resp, fmt.Errorf("while getting app %s, error: %v", appId, e)
+	}
+	return s.ownership(app)
+}
+
+func (s *AppsServiceOp) ownership(app *App) (*AppOwnership, *Response, error) {
+	o := &AppOwnership{}
+	if app.IsCompanyApp() {
+		companyApp := app.CompanyApp()
+		o.CompanyApp = &companyApp
+		company, resp, e := s.client.Companies.Get(app.CompanyName)
+		if e != nil {
+			return nil, resp, fmt.Errorf("while getting company %s, error: %v", app.CompanyName, e)
+		}
+		o.Company = company
+		return o, resp, nil
+	}
+	developerApp := app.DeveloperApp()
+	o.DeveloperApp = &developerApp
+	developer, resp, e := s.client.Developers.Get(app.DeveloperId)
+	if e != nil {
+		return nil, resp, fmt.Errorf("while getting developer %s, error: %v", app.DeveloperId, e)
+	}
+	o.Developer = developer
+	return o, resp, nil
+}
+
+// LookupConsumerKey finds the credential with the given consumer key, the app
+// it belongs to, and the developer or company that owns the app. The Admin API
+// cannot search by key, so the apps of the organization are retrieved with
+// their credentials, a page of 1000 at a time, until the key is found. In a
+// large organization this takes one request per page, and the whole
+// organization is read when the key does not exist.
+func (s *AppsServiceOp) LookupConsumerKey(consumerKey string) (*AppOwnership, *Response, error) {
+	var found *App
+	var credential Credential
+	resp, e := s.forEachPage(&AppsListOptions{IncludeCredentials: true}, func(page []App) bool {
+		for i, app := range page {
+			for _, c := range app.Credentials {
+				if c.ConsumerKey == consumerKey {
+					found, credential = &page[i], c
+					return false
+				}
+			}
+		}
+		return true
+	})
+	if e != nil {
+		return nil, resp, fmt.Errorf("while listing apps, error: %v", e)
+	}
+	if found == nil {
+		return nil, resp, fmt.Errorf("no app has consumer key %s", consumerKey)
+	}
+	o, resp, e := s.ownership(found)
+	if e != nil {
+		return nil, resp, e
+	}
+	o.Credential = &credential
+	return o, resp, nil
+}
+
+// ListWithAttribute returns the developers that have the named attribute.
+// When value is not empty, only developers whose attribute has that value are
+// returned.
+func (s *DevelopersServiceOp) ListWithAttribute(name, value string) ([]Developer, *Response, error) {
+	all, resp, e := s.ListExpanded()
+	if e != nil {
+		return nil, resp, fmt.Errorf("while listing developers, error: %v", e)
+	}
+	return filterDevelopersByAttribute(all, name, value), resp, nil
+}
+
+func filterDevelopersByAttribute(developers []Developer, name, value string) []Developer {
+	matched := []Developer{}
+	for _, d := range developers {
+		if v, ok := d.Attributes.Get(name); ok && (value == "" || v == value) {
+			matched = append(matched, d)
+		}
+	}
+	return matched
+}
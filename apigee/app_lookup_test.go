@@ -0,0 +1,84 @@
+package apigee
+
+import (
+	"testing"
+)
+
+func TestFilterDevelopersByAttribute(t *testing.T) {
+	developers := []Developer{
+		{Email: "a@example.com", Attributes: Attributes{{Name: "partner", Value: "acme"}}},
+		{Email: "b@example.com", Attributes: Attributes{{Name: "partner", Value: "globex"}}},
+		{Email: "c@example.com"},
+	}
+	tt := []struct {
+		desc     string
+		name     string
+		value    string
+		expected int
+	}{
+		{"any value", "partner", "", 2},
+		{"one value", "partner", "acme", 1},
+		{"no match", "partner", "initech", 0},
+		{"no attribute", "tier", "", 0},
+	}
+	for _, test := range tt {
+		if got := filterDevelopersByAttribute(developers, test.name, test.value); len(got) != test.expected {
+			t.Errorf("%s: got=%v, expected %d", test.desc, got, test.expected)
+		}
+	}
+}
+
+func TestLookupApp(t *testing.T) {
+	client := NewClientForTesting(t)
+	dev, e := randomDeveloperFromTemplate()
+	createdDeveloper, _, e := client.Developers.Create(dev)
+	if e != nil {
+		t.Errorf("while creating Edge developer, error:\n%#v\n", e)
+		return
+	}
+	defer client.Developers.Delete(createdDeveloper.Email)
+
+	devapp, e := randomAppFromTemplate()
+	createdApp, _, e := client.DeveloperApps.Create(createdDeveloper.Email, devapp)
+	if e != nil {
+		t.Errorf("while creating developer app, error:\n%#v\n", e)
+		return
+	}
+	defer client.DeveloperApps.Delete(createdDeveloper.Email, createdApp.Name)
+
+	o, _, e := client.Apps.Lookup(createdApp.Id)
+	if e != nil {
+		t.Errorf("while looking up app, error:\n%#v\n", e)
+		return
+	}
+	if o.Owner() != createdDeveloper.Email || o.AppName() != createdApp.Name {
+		t.Errorf("Lookup: got owner=%s app=%s", o.Owner(), o.AppName())
+	}
+
+	if len(createdApp.Credentials) == 0 {
+		t.Errorf("created app has no credentials")
+		return
+	}
+	key := createdApp.Credentials[0].ConsumerKey
+	o, _, e = client.Apps.LookupConsumerKey(key)
+	if e != nil {
+		t.Errorf("while looking up consumer key, error:\n%#v\n", e)
+		return
+	}
+	if o.Owner() != createdDeveloper.Email || o.Credential.ConsumerKey != key {
+		t.Errorf("LookupConsumerKey: got owner=%s credential=%v", o.Owner(), o.Credential)
+	}
+
+	developers, _, e := client.Developers.ListWithAttribute("tag1", "created by golang")
+	if e != nil {
+		t.Errorf("while looking up developers by attribute, error:\n%#v\n", e)
+		return
+	}
+	found := false
+	for _, d := range developers {
+		found = found || d.Email == createdDeveloper.Email
+	}
+	if !found {
+		t.Errorf("ListWithAttribute: %s not found", createdDeveloper.Email)
+	}
+}
@@ -0,0 +1,242 @@
+package apigee
+
+import (
+	"encoding/json"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+)
+
+// AppsService is an interface for interfacing with the Apigee Edge Admin API
+// dealing with all the apps of an organization, whether owned by a developer
+// or a company.
+type AppsService interface {
+	Get(string) (*App, *Response, error)
+	GetCredentialReport(time.Duration) (*CredentialReport, error)
+	List(*AppsListOptions) ([]string, *Response, error)
+	ListAll(*AppsListOptions) ([]App, *Response, error)
+	ListExpanded(*AppsListOptions) ([]App, *Response, error)
+	ListForProducts(...string) ([]AppAccess, *Response, error)
+	Lookup(string) (*AppOwnership, *Response, error)
+	LookupConsumerKey(string) (*AppOwnership, *Response, error)
+}
+
+type AppsServiceOp struct {
+	client *ApigeeClient
+}
+
+var _ AppsService = &AppsServiceOp{}
+
+// The filters accepted by AppsService.
+const (
+	AppStatusApproved = "approved"
+	AppStatusRevoked  = "revoked"
+	DeveloperAppType  = "developer"
+	CompanyAppType    = "company"
+)
+
+// The number of apps requested in each page of ListAll.
+const appsPageSize = 1000
+
+// App is an app retrieved through the organization, as opposed to through its
+// owner. DeveloperId is set for a developer app and CompanyName for a company app.
+type App struct {
+	// Deprecated: use AppId. ApigeeId was the only field of the placeholder App
+	// that this type replaced, and is kept so that code using it still builds.
+	// It is set to AppId when an app is read from JSON.
+	ApigeeId string `json:"-"`
+
+	AppFamily      string       `json:"appFamily,omitempty"`
+	AppId          string       `json:"appId,omitempty"`
+	Attributes     Attributes   `json:"attributes,omitempty"`
+	CallbackUrl    string       `json:"callbackUrl,omitempty"`
+	CompanyName    string       `json:"companyName,omitempty"`
+	CreatedAt      Timestamp    `json:"createdAt,omitempty"`
+	CreatedBy      string       `json:"createdBy,omitempty"`
+	Credentials    []Credential `json:"credentials,omitempty"`
+	DeveloperId    string       `json:"developerId,omitempty"`
+	LastModifiedAt Timestamp    `json:"lastModifiedAt,omitempty"`
+	LastModifiedBy string       `json:"lastModifiedBy,omitempty"`
+	Name           string       `json:"name,omitempty"`
+	Scopes         []string     `json:"scopes,omitempty"`
+	Status         string       `json:"status,omitempty"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, setting ApigeeId.
+func (a *App) UnmarshalJSON(data []byte) error {
+	type app App
+	if e := json.Unmarshal(data, (*app)(a)); e != nil {
+		return e
+	}
+	a.ApigeeId = a.AppId
+	return nil
+}
+
+// IsCompanyApp reports whether the app is owned by a company.
+func (a App) IsCompanyApp() bool {
+	return a.CompanyName != ""
+}
+
+// DeveloperApp returns the app in the form used by DeveloperAppsService.
+func (a App) DeveloperApp() DeveloperApp {
+	return DeveloperApp{
+		Attributes:  a.Attributes,
+		CallbackUrl: a.CallbackUrl,
+		Credentials: a.Credentials,
+		DeveloperId: a.DeveloperId,
+		Id:          a.AppId,
+		Name:        a.Name,
+		Scopes:      a.Scopes,
+		Status:      a.Status,
+	}
+}
+
+// CompanyApp returns the app in the form used by CompanyAppsService.
+func (a App) CompanyApp() CompanyApp {
+	return CompanyApp{
+		AppFamily:   a.AppFamily,
+		AppId:       a.AppId,
+		Attributes:  a.Attributes,
+		CallbackUrl: a.CallbackUrl,
+		CompanyName: a.CompanyName,
+		Credentials: a.Credentials,
+		Name:        a.Name,
+		Scopes:      a.Scopes,
+		Status:      a.Status,
+	}
+}
+
+// AppsListOptions filters the apps listed by AppsService. All fields are optional.
+type AppsListOptions struct {
+	// AppStatusApproved or AppStatusRevoked.
+	Status string
+
+	// DeveloperAppType or CompanyAppType.
+	AppType string
+
+	// Include the credentials of each app. Applies to ListExpanded and ListAll.
+	IncludeCredentials bool
+
+	// The number of apps to return. Ignored by ListAll.
+	Rows int
+
+	// The ID of the first app to return. Ignored by ListAll.
+	StartKey string
+}
+
+func appsListPath(opts *AppsListOptions, expand bool) string {
+	q := url.Values{}
+	if expand {
+		q.Add("expand", "true")
+	}
+	if opts != nil {
+		if opts.Status != "" {
+			q.Add("status", opts.Status)
+		}
+		if opts.AppType != "" {
+			q.Add("apptype", opts.AppType)
+		}
+		if expand && opts.IncludeCredentials {
+			q.Add("includeCred", "true")
+		}
+		if opts.Rows > 0 {
+			q.Add("rows", strconv.Itoa(opts.Rows))
+		}
+		if opts.StartKey != "" {
+			q.Add("startKey", opts.StartKey)
+		}
+	}
+	if len(q) == 0 {
+		return appPath
+	}
+	return appPath + "?" + q.Encode()
+}
+
+// List retrieves the IDs of the apps in the organization.
+func (s *AppsServiceOp) List(opts *AppsListOptions) ([]string, *Response, error) {
+	req, e := s.client.NewRequest("GET", appsListPath(opts, false), nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	idlist := make([]string, 0)
+	resp, e := s.client.Do(req, &idlist)
+	if e != nil {
+		return nil, resp, e
+	}
+	return idlist, resp, e
+}
+
+// ListExpanded retrieves the apps in the organization, with full details.
+func (s *AppsServiceOp) ListExpanded(opts *AppsListOptions) ([]App, *Response, error) {
+	req, e := s.client.NewRequest("GET", appsListPath(opts, true), nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	apps := struct {
+		Apps []App `json:"app"`
+	}{}
+	resp, e := s.client.Do(req, &apps)
+	if e != nil {
+		return nil, resp, e
+	}
+	if apps.Apps == nil {
+		apps.Apps = []App{}
+	}
+	return apps.Apps, resp, e
+}
+
+// ListAll retrieves every app in the organization that matches the filters in
+// opts, with full details, requesting them a page at a time.
+func (s *AppsServiceOp) ListAll(opts *AppsListOptions) ([]App, *Response, error) {
+	all := []App{}
+	resp, e := s.forEachPage(opts, func(page []App) bool {
+		all = append(all, page...)
+		return true
+	})
+	if e != nil {
+		return nil, resp, e
+	}
+	return all, resp, e
+}
+
+// forEachPage retrieves the apps that match the filters in opts a page at a
+// time, passing each page to fn, until there are no more or fn returns false.
+func (s *AppsServiceOp) forEachPage(opts *AppsListOptions, fn func([]App) bool) (*Response, error) {
+	pageOpts := AppsListOptions{}
+	if opts != nil {
+		pageOpts = *opts
+	}
+	pageOpts.Rows = appsPageSize
+	pageOpts.StartKey = ""
+	for {
+		page, resp, e := s.ListExpanded(&pageOpts)
+		if e != nil {
+			return resp, e
+		}
+		found := page
+		// Each page after the first begins with the last app of the previous page.
+		if pageOpts.StartKey != "" && len(found) > 0 && found[0].AppId == pageOpts.StartKey {
+			found = found[1:]
+		}
+		if !fn(found) || len(page) < appsPageSize || len(found) == 0 {
+			return resp, e
+		}
+		pageOpts.StartKey = found[len(found)-1].AppId
+	}
+}
+
+// Get retrieves an app by its ID.
+func (s *AppsServiceOp) Get(appId string) (*App, *Response, error) {
+	path := path.Join(appPath, appId)
+	req, e := s.client.NewRequest("GET", path, nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	returnedApp := App{}
+	resp, e := s.client.Do(req, &returnedApp)
+	if e != nil {
+		return nil, resp, e
+	}
+	return &returnedApp, resp, e
+}
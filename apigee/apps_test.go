@@ -0,0 +1,84 @@
+package apigee
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAppsListPath(t *testing.T) {
+	tt := []struct {
+		desc     string
+		opts     *AppsListOptions
+		expand   bool
+		expected string
+	}{
+		{"no options", nil, false, "apps"},
+		{"expand", nil, true, "apps?expand=true"},
+		{"filters", &AppsListOptions{Status: AppStatusRevoked, AppType: CompanyAppType, Rows: 10, StartKey: "abc"}, false,
+			"apps?apptype=company&rows=10&startKey=abc&status=revoked"},
+		{"credentials need expand", &AppsListOptions{IncludeCredentials: true}, false, "apps"},
+		{"credentials", &AppsListOptions{IncludeCredentials: true}, true, "apps?expand=true&includeCred=true"},
+	}
+	for _, test := range tt {
+		if got := appsListPath(test.opts, test.expand); got != test.expected {
+			t.Errorf("%s: got=%s, expected=%s", test.desc, got, test.expected)
+		}
+	}
+}
+
+func TestAppConversions(t *testing.T) {
+	apps := struct {
+		Apps []App `json:"app"`
+	}{}
+	e := json.Unmarshal([]byte(`{"app": [
+  {"appId": "id-1", "name": "one", "developerId": "dev-1", "status": "approved",
+   "credentials": [{"consumerKey": "k1", "apiProducts": [{"apiproduct": "gold", "status": "approved"}]}]},
+  {"appId": "id-2", "name": "two", "companyName": "acme", "appFamily": "default", "status": "revoked"}
+]}`), &apps)
+	if e != nil {
+		t.Fatalf("while unmarshaling, error: %v", e)
+	}
+	if len(apps.Apps) != 2 {
+		t.Fatalf("got=%v", apps.Apps)
+	}
+	dev, company := apps.Apps[0], apps.Apps[1]
+	if dev.ApigeeId != "id-1" || company.ApigeeId != "id-2" {
+		t.Errorf("ApigeeId: got=%q, %q", dev.ApigeeId, company.ApigeeId)
+	}
+	if dev.IsCompanyApp() || !company.IsCompanyApp() {
+		t.Errorf("IsCompanyApp: got=%t, %t", dev.IsCompanyApp(), company.IsCompanyApp())
+	}
+	if got := dev.DeveloperApp(); got.Id != "id-1" || got.DeveloperId != "dev-1" || got.Credentials[0].ConsumerKey != "k1" {
+		t.Errorf("DeveloperApp: got=%#v", got)
+	}
+	if got := company.CompanyApp(); got.AppId != "id-2" || got.CompanyName != "acme" || got.AppFamily != "default" {
+		t.Errorf("CompanyApp: got=%#v", got)
+	}
+}
+
+func TestAppsList(t *testing.T) {
+	client := NewClientForTesting(t)
+	ids, _, e := client.Apps.List(&AppsListOptions{Rows: 5})
+	if e != nil {
+		t.Errorf("while listing apps, error:\n%#v\n", e)
+		return
+	}
+	if len(ids) > 5 {
+		t.Errorf("asked for 5 rows, got %d", len(ids))
+	}
+	apps, _, e := client.Apps.ListExpanded(&AppsListOptions{Rows: 5, IncludeCredentials: true})
+	if e != nil {
+		t.Errorf("while listing expanded apps, error:\n%#v\n", e)
+		return
+	}
+	for _, app := range apps {
+		got, _, e := client.Apps.Get(app.AppId)
+		if e != nil {
+			t.Errorf("while getting app %s, error:\n%#v\n", app.AppId, e)
+			return
+		}
+		if got.Name != app.Name {
+			t.Errorf("Get(%s): got=%s, expected=%s", app.AppId, got.Name, app.Name)
+		}
+	}
+}
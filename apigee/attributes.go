@@ -0,0 +1,220 @@
+package apigee
+
+import (
+	"net/http"
+	"path"
+)
+
+const attributesPath = "attributes"
+
+// Attributes is the collection of custom attributes of an API Product,
+// developer, app, company or credential.
+type Attributes []Attribute
+
+// Get returns the value of the named attribute, and whether it is present.
+func (a Attributes) Get(name string) (string, bool) {
+	for _, attr := range a {
+		if attr.Name == name {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// Set sets the value of the named attribute, adding it if it is not present.
+func (a *Attributes) Set(name, value string) {
+	for i := range *a {
+		if (*a)[i].Name == name {
+			(*a)[i].Value = value
+			return
+		}
+	}
+	*a = append(*a, Attribute{Name: name, Value: value})
+}
+
+// Delete removes the named attribute, and reports whether it was present.
+func (a *Attributes) Delete(name string) bool {
+	kept := Attributes{}
+	for _, attr := range *a {
+		if attr.Name != name {
+			kept = append(kept, attr)
+		}
+	}
+	deleted := len(kept) != len(*a)
+	*a = kept
+	return deleted
+}
+
+// Map returns the attributes as a map from name to value.
+func (a Attributes) Map() map[string]string {
+	m := map[string]string{}
+	for _, attr := range a {
+		m[attr.Name] = attr.Value
+	}
+	return m
+}
+
+// AttributeOwner identifies the entity whose attributes an AttributesService
+// call reads or writes. Use one of the functions below to make one.
+type AttributeOwner struct {
+	path string
+}
+
+// String returns the path of the owner, relative to the organization.
+func (o AttributeOwner) String() string {
+	return o.path
+}
+
+// ProductAttributes identifies the attributes of an API Product.
+func ProductAttributes(productName string) AttributeOwner {
+	return AttributeOwner{path.Join(productsPath, productName)}
+}
+
+// DeveloperAttributes identifies the attributes of a developer.
+func DeveloperAttributes(developerEmailOrId string) AttributeOwner {
+	return AttributeOwner{path.Join(developersPath, developerEmailOrId)}
+}
+
+// DeveloperAppAttributes identifies the attributes of a developer app.
+func DeveloperAppAttributes(developerEmail, appName string) AttributeOwner {
+	return AttributeOwner{path.Join(developersPath, developerEmail, appPath, appName)}
+}
+
+// DeveloperAppKeyAttributes identifies the attributes of a developer app's consumer key.
+func DeveloperAppKeyAttributes(developerEmail, appName, consumerKey string) AttributeOwner {
+	return AttributeOwner{path.Join(developersPath, developerEmail, appPath, appName, keysPath, consumerKey)}
+}
+
+// CompanyAttributes identifies the attributes of a company.
+func CompanyAttributes(companyName string) AttributeOwner {
+	return AttributeOwner{path.Join(companiesPath, companyName)}
+}
+
+// CompanyAppAttributes identifies the attributes of a company app.
+func CompanyAppAttributes(companyName, appName string) AttributeOwner {
+	return AttributeOwner{path.Join(companiesPath, companyName, appPath, appName)}
+}
+
+// CompanyAppKeyAttributes identifies the attributes of a company app's consumer key.
+func CompanyAppKeyAttributes(companyName, appName, consumerKey string) AttributeOwner {
+	return AttributeOwner{path.Join(companiesPath, companyName, appPath, appName, keysPath, consumerKey)}
+}
+
+// AttributesService is an interface for interfacing with the Apigee Edge Admin API
+// dealing with the custom attributes of products, developers, apps, companies and
+// credentials. Unlike updating the entity itself, it reads and writes single
+// attributes without disturbing the others.
+type AttributesService interface {
+	Delete(AttributeOwner, string) (*Attribute, *Response, error)
+	Get(AttributeOwner, string) (*Attribute, *Response, error)
+	List(AttributeOwner) (Attributes, *Response, error)
+	Replace(AttributeOwner, Attributes) (Attributes, *Response, error)
+	Set(AttributeOwner, string, string) (*Attribute, *Response, error)
+}
+
+type AttributesServiceOp struct {
+	client *ApigeeClient
+}
+
+var _ AttributesService = &AttributesServiceOp{}
+
+// attributeList is the form in which Edge sends and receives a collection of attributes.
+type attributeList struct {
+	Attributes Attributes `json:"attribute"`
+}
+
+// List retrieves all the attributes of an entity.
+func (s *AttributesServiceOp) List(owner AttributeOwner) (Attributes, *Response, error) {
+	path := path.Join(owner.path, attributesPath)
+	req, e := s.client.NewRequest("GET", path, nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	list := attributeList{}
+	resp, e := s.client.Do(req, &list)
+	if e != nil {
+		return nil, resp, e
+	}
+	if list.Attributes == nil {
+		list.Attributes = Attributes{}
+	}
+	return list.Attributes, resp, e
+}
+
+// Replace replaces all the attributes of an entity. Attributes not in attrs are removed.
+func (s *AttributesServiceOp) Replace(owner AttributeOwner, attrs Attributes) (Attributes, *Response, error) {
+	path := path.Join(owner.path, attributesPath)
+	req, e := s.client.NewRequest("POST", path, attributeList{attrs})
+	if e != nil {
+		return nil, nil, e
+	}
+	list := attributeList{}
+	resp, e := s.client.Do(req, &list)
+	if e != nil {
+		return nil, resp, e
+	}
+	if list.Attributes == nil {
+		list.Attributes = Attributes{}
+	}
+	return list.Attributes, resp, e
+}
+
+// Get retrieves a single attribute of an entity.
+func (s *AttributesServiceOp) Get(owner AttributeOwner, name string) (*Attribute, *Response, error) {
+	path := path.Join(owner.path, attributesPath, name)
+	req, e := s.client.NewRequest("GET", path, nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	attr := Attribute{}
+	resp, e := s.client.Do(req, &attr)
+	if e != nil {
+		return nil, resp, e
+	}
+	return &attr, resp, e
+}
+
+// Set sets a single attribute of an entity. Edge updates only attributes that
+// exist, so a new attribute is added by replacing the collection with one that
+// includes it.
+func (s *AttributesServiceOp) Set(owner AttributeOwner, name, value string) (*Attribute, *Response, error) {
+	path := path.Join(owner.path, attributesPath, name)
+	req, e := s.client.NewRequest("POST", path, Attribute{Name: name, Value: value})
+	if e != nil {
+		return nil, nil, e
+	}
+	attr := Attribute{}
+	resp, e := s.client.Do(req, &attr)
+	if e == nil {
+		return &attr, resp, e
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		return nil, resp, e
+	}
+
+	attrs, resp, e := s.List(owner)
+	if e != nil {
+		return nil, resp, e
+	}
+	attrs.Set(name, value)
+	_, resp, e = s.Replace(owner, attrs)
+	if e != nil {
+		return nil, resp, e
+	}
+	return &Attribute{Name: name, Value: value}, resp, e
+}
+
+// Delete removes a single attribute from an entity.
+func (s *AttributesServiceOp) Delete(owner AttributeOwner, name string) (*Attribute, *Response, error) {
+	path := path.Join(owner.path, attributesPath, name)
+	req, e := s.client.NewRequest("DELETE", path, nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	attr := Attribute{}
+	resp, e := s.client.Do(req, &attr)
+	if e != nil {
+		return nil, resp, e
+	}
+	return &attr, resp, e
+}
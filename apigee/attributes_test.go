@@ -0,0 +1,88 @@
+package apigee
+
+import (
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestAttributes(t *testing.T) {
+	a := Attributes{{Name: "tier", Value: "gold"}, {Name: "region", Value: "emea"}}
+	if v, ok := a.Get("tier"); !ok || v != "gold" {
+		t.Errorf("Get(tier): got=%q, %t", v, ok)
+	}
+	if _, ok := a.Get("missing"); ok {
+		t.Errorf("Get(missing): expected not present")
+	}
+	a.Set("tier", "silver")
+	a.Set("owner", "ops")
+	expected := Attributes{{Name: "tier", Value: "silver"}, {Name: "region", Value: "emea"}, {Name: "owner", Value: "ops"}}
+	if !reflect.DeepEqual(a, expected) {
+		t.Errorf("Set: got=%v, expected=%v", a, expected)
+	}
+	if !a.Delete("region") || a.Delete("region") {
+		t.Errorf("Delete: expected true, then false")
+	}
+	if got := a.Map(); !reflect.DeepEqual(got, map[string]string{"tier": "silver", "owner": "ops"}) {
+		t.Errorf("Map: got=%v", got)
+	}
+
+	var empty Attributes
+	empty.Set("a", "b")
+	if len(empty) != 1 {
+		t.Errorf("Set on nil: got=%v", empty)
+	}
+}
+
+func TestAttributeList(t *testing.T) {
+	list := attributeList{}
+	e := json.Unmarshal([]byte(`{"attribute": [{"name": "tier", "value": "gold"}]}`), &list)
+	if e != nil {
+		t.Fatalf("while unmarshaling, error: %v", e)
+	}
+	if v, _ := list.Attributes.Get("tier"); v != "gold" {
+		t.Errorf("got=%v", list)
+	}
+	if got := ProductAttributes("p1").String(); got != "apiproducts/p1" {
+		t.Errorf("ProductAttributes: got=%s", got)
+	}
+	if got := CompanyAppKeyAttributes("acme", "app1", "key1").String(); got != "companies/acme/apps/app1/keys/key1" {
+		t.Errorf("CompanyAppKeyAttributes: got=%s", got)
+	}
+}
+
+func TestProductAttributes(t *testing.T) {
+	client := NewClientForTesting(t)
+	namelist, _, e := client.Proxies.List()
+	if e != nil || len(namelist) == 0 {
+		t.Errorf("while listing proxies, error:\n%#v\n", e)
+		return
+	}
+	product, e := randomProductFromTemplate(namelist[rand.Intn(len(namelist))])
+	createdProduct, _, e := client.Products.Create(product)
+	if e != nil {
+		t.Errorf("while creating Apigee product, error:\n%#v\n", e)
+		return
+	}
+	defer client.Products.Delete(createdProduct.Name)
+
+	owner := ProductAttributes(createdProduct.Name)
+	_, _, e = client.Attributes.Set(owner, "added", "by go test")
+	if e != nil {
+		t.Errorf("while setting attribute, error:\n%#v\n", e)
+		return
+	}
+	attrs, _, e := client.Attributes.List(owner)
+	if e != nil {
+		t.Errorf("while listing attributes, error:\n%#v\n", e)
+		return
+	}
+	if v, _ := attrs.Get("added"); v != "by go test" || len(attrs) != len(createdProduct.Attributes)+1 {
+		t.Errorf("after Set: got=%v", attrs)
+	}
+	_, _, e = client.Attributes.Delete(owner, "added")
+	if e != nil {
+		t.Errorf("while deleting attribute, error:\n%#v\n", e)
+	}
+}
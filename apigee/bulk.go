@@ -0,0 +1,749 @@
+package apigee
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BulkDeveloper is a developer together with its apps, as read and written by
+// the Import and Export methods of DevelopersService. Here and in BulkApp and
+// BulkCredential, Rows lists the CSV rows that ReadDevelopersCSV read the
+// record from, so that Import can report them.
+type BulkDeveloper struct {
+	Email      string     `json:"email"`
+	FirstName  string     `json:"firstName,omitempty"`
+	LastName   string     `json:"lastName,omitempty"`
+	UserName   string     `json:"userName,omitempty"`
+	Attributes Attributes `json:"attributes,omitempty"`
+	Apps       []BulkApp  `json:"apps,omitempty"`
+	Rows       []int      `json:"-"`
+}
+
+// BulkApp is a developer app together with its credentials.
+type BulkApp struct {
+	Name        string           `json:"name"`
+	CallbackUrl string           `json:"callbackUrl,omitempty"`
+	Attributes  Attributes       `json:"attributes,omitempty"`
+	Credentials []BulkCredential `json:"credentials,omitempty"`
+	Rows        []int            `json:"-"`
+}
+
+// BulkCredential is a consumer key of a developer app. When ConsumerKey is
+// empty, the credential refers to the key Edge generates when it creates the
+// app. Status is "approved" or "revoked"; when empty, the status of an
+// existing key is left alone and a new key is approved. The status of each
+// API product is "approved", "pending" or "revoked"; when empty, the product
+// is approved or left pending according to its approval type. Edge sets the
+// expiry of a key only when it generates the key, so an import cannot set
+// ExpiresAt: a credential whose ExpiresAt differs from the key's fails, and one
+// with an ExpiresAt and a key that does not exist yet fails without the key
+// being created. When ExpiresAt is nil, expiry is not checked.
+type BulkCredential struct {
+	ConsumerKey    string                 `json:"consumerKey,omitempty"`
+	ConsumerSecret string                 `json:"consumerSecret,omitempty"`
+	ApiProducts    []CredentialApiProduct `json:"apiProducts,omitempty"`
+	Status         string                 `json:"status,omitempty"`
+	ExpiresAt      *time.Time             `json:"expiresAt,omitempty"`
+	Attributes     Attributes             `json:"attributes,omitempty"`
+	Rows           []int                  `json:"-"`
+}
+
+// BulkAction is what Import did with one developer, app or credential.
+type BulkAction string
+
+const (
+	BulkCreated   BulkAction = "created"
+	BulkUpdated   BulkAction = "updated"
+	BulkUnchanged BulkAction = "unchanged"
+	BulkFailed    BulkAction = "failed"
+	// Not imported because the developer or app it belongs to failed.
+	BulkSkipped BulkAction = "skipped"
+)
+
+// BulkResult is the outcome of importing one developer, app or credential. App
+// is empty for a developer, and ConsumerKey is empty for a developer or app.
+// Rows lists the CSV rows the developer, app or credential was read from, if
+// it was read with ReadDevelopersCSV.
+type BulkResult struct {
+	Developer   string     `json:"developer"`
+	App         string     `json:"app,omitempty"`
+	ConsumerKey string     `json:"consumerKey,omitempty"`
+	Rows        []int      `json:"rows,omitempty"`
+	Action      BulkAction `json:"action"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// BulkOptions controls the Import and Export methods of DevelopersService.
+type BulkOptions struct {
+	// Optional. The number of developers to import or export at once. Defaults to 4.
+	Parallelism int
+}
+
+// Import creates or updates each developer, its apps and their
+// credentials, so that importing the same records twice changes nothing the
+// second time. Fields left empty in a record keep their current values, and
+// attributes are merged: those in the record are added or updated, and others
+// are left alone. Credentials are imported with their consumer keys and
+// secrets, and API products are added to them. When a new app is given only
+// credentials with explicit keys, the key Edge generated for it is deleted.
+//
+// The records are checked before anything is imported, and an error is
+// returned if they are invalid. Otherwise the results list the outcome for
+// each developer, followed by each of its apps and their credentials, in the
+// order of the records.
+func (s *DevelopersServiceOp) Import(developers []BulkDeveloper, opts *BulkOptions) ([]BulkResult, error) {
+	if opts == nil {
+		opts = &BulkOptions{}
+	}
+	if e := validateBulkDevelopers(developers); e != nil {
+		return nil, e
+	}
+	results := make([][]BulkResult, len(developers))
+	runParallel(len(developers), opts.Parallelism, func(i int) error {
+		results[i] = importDeveloper(s.client, developers[i])
+		return nil
+	})
+	all := []BulkResult{}
+	for _, r := range results {
+		all = append(all, r...)
+	}
+	return all, nil
+}
+
+// validateBulkDevelopers checks that the records name each developer and app
+// once, and each consumer key once.
+func validateBulkDevelopers(developers []BulkDeveloper) error {
+	emails := map[string]bool{}
+	keys := map[string]bool{}
+	for _, d := range developers {
+		if d.Email == "" {
+			return errors.New("a developer has no email")
+		}
+		email := strings.ToLower(d.Email)
+		if emails[email] {
+			return fmt.Errorf("developer %s is listed more than once", d.Email)
+		}
+		emails[email] = true
+		apps := map[string]bool{}
+		for _, a := range d.Apps {
+			if a.Name == "" {
+				return fmt.Errorf("an app of developer %s has no name", d.Email)
+			}
+			if apps[a.Name] {
+				return fmt.Errorf("app %s of developer %s is listed more than once", a.Name, d.Email)
+			}
+			apps[a.Name] = true
+			generated := false
+			for _, c := range a.Credentials {
+				if c.Status != "" && c.Status != "approved" && c.Status != "revoked" {
+					return fmt.Errorf("app %s of developer %s: unknown key status %q", a.Name, d.Email, c.Status)
+				}
+				for _, p := range c.ApiProducts {
+					if p.Status != "" && p.Status != "approved" && p.Status != "pending" && p.Status != "revoked" {
+						return fmt.Errorf("app %s of developer %s: unknown status %q for API product %s", a.Name, d.Email, p.Status, p.ApiProduct)
+					}
+				}
+				if c.ConsumerKey == "" {
+					if generated {
+						return fmt.Errorf("app %s of developer %s has more than one credential without a consumer key", a.Name, d.Email)
+					}
+					generated = true
+					continue
+				}
+				if keys[c.ConsumerKey] {
+					return fmt.Errorf("consumer key %s is listed more than once", c.ConsumerKey)
+				}
+				keys[c.ConsumerKey] = true
+			}
+		}
+	}
+	return nil
+}
+
+func bulkResult(r BulkResult, action BulkAction, e error) BulkResult {
+	if e != nil {
+		r.Action = BulkFailed
+		r.Error = e.Error()
+		return r
+	}
+	r.Action = action
+	return r
+}
+
+// skippedApp returns the results for an app that was not imported, and its credentials.
+func skippedApp(email string, a BulkApp) []BulkResult {
+	results := []BulkResult{{Developer: email, App: a.Name, Rows: a.Rows, Action: BulkSkipped}}
+	for _, c := range a.Credentials {
+		results = append(results, BulkResult{Developer: email, App: a.Name, ConsumerKey: c.ConsumerKey, Rows: c.Rows, Action: BulkSkipped})
+	}
+	return results
+}
+
+func importDeveloper(client *ApigeeClient, d BulkDeveloper) []BulkResult {
+	action, e := upsertDeveloper(client, d)
+	results := []BulkResult{bulkResult(BulkResult{Developer: d.Email, Rows: d.Rows}, action, e)}
+	for _, a := range d.Apps {
+		if e != nil {
+			results = append(results, skippedApp(d.Email, a)...)
+			continue
+		}
+		results = append(results, importApp(client, d.Email, a)...)
+	}
+	return results
+}
+
+// setIfChanged sets *field to value, unless value is empty or the same, and
+// reports whether it did.
+func setIfChanged(field *string, value string) bool {
+	if value == "" || *field == value {
+		return false
+	}
+	*field = value
+	return true
+}
+
+// mergeAttributes returns existing with the attributes in given added or
+// updated, and whether that changed anything.
+func mergeAttributes(existing, given Attributes) (Attributes, bool) {
+	merged := append(Attributes{}, existing...)
+	changed := false
+	for _, attr := range given {
+		if value, ok := merged.Get(attr.Name); ok && value == attr.Value {
+			continue
+		}
+		merged.Set(attr.Name, attr.Value)
+		changed = true
+	}
+	return merged, changed
+}
+
+func isNotFound(resp *Response) bool {
+	return resp != nil && resp.StatusCode == http.StatusNotFound
+}
+
+func upsertDeveloper(client *ApigeeClient, d BulkDeveloper) (BulkAction, error) {
+	existing, resp, e := client.Developers.Get(d.Email)
+	if e != nil {
+		if !isNotFound(resp) {
+			return "", fmt.Errorf("while getting developer, error: %v", e)
+		}
+		dev := Developer{Email: d.Email, FirstName: d.FirstName, LastName: d.LastName, UserName: d.UserName, Attributes: d.Attributes}
+		if _, _, e := client.Developers.Create(dev); e != nil {
+			return "", fmt.Errorf("while creating developer, error: %v", e)
+		}
+		return BulkCreated, nil
+	}
+	dev := Developer{Email: existing.Email, FirstName: existing.FirstName, LastName: existing.LastName, UserName: existing.UserName}
+	changed := setIfChanged(&dev.FirstName, d.FirstName)
+	changed = setIfChanged(&dev.LastName, d.LastName) || changed
+	changed = setIfChanged(&dev.UserName, d.UserName) || changed
+	attrs, attrsChanged := mergeAttributes(existing.Attributes, d.Attributes)
+	if !changed && !attrsChanged {
+		return BulkUnchanged, nil
+	}
+	dev.Attributes = attrs
+	if _, _, e := client.Developers.Update(dev); e != nil {
+		return "", fmt.Errorf("while updating developer, error: %v", e)
+	}
+	return BulkUpdated, nil
+}
+
+func importApp(client *ApigeeClient, email string, a BulkApp) []BulkResult {
+	app, created, action, e := upsertApp(client, email, a)
+	result := bulkResult(BulkResult{Developer: email, App: a.Name, Rows: a.Rows}, action, e)
+	if e != nil {
+		return append([]BulkResult{result}, skippedApp(email, a)[1:]...)
+	}
+	results := []BulkResult{result}
+
+	generated := ""
+	if created && len(app.Credentials) > 0 {
+		generated = app.Credentials[0].ConsumerKey
+	}
+	keepGenerated := len(a.Credentials) == 0
+	for _, c := range a.Credentials {
+		key := c.ConsumerKey
+		if key == "" && len(app.Credentials) > 0 {
+			key = app.Credentials[0].ConsumerKey
+		}
+		keepGenerated = keepGenerated || key == generated
+		action, e := upsertCredential(client, email, app, c)
+		results = append(results, bulkResult(BulkResult{Developer: email, App: a.Name, ConsumerKey: key, Rows: c.Rows}, action, e))
+	}
+	if generated != "" && !keepGenerated {
+		if _, e := client.DeveloperAppCredentials.Delete(email, a.Name, generated); e != nil {
+			results = append(results, BulkResult{Developer: email, App: a.Name, ConsumerKey: generated, Rows: a.Rows, Action: BulkFailed,
+				Error: fmt.Sprintf("while deleting generated key, error: %v", e)})
+		}
+	}
+	return results
+}
+
+// upsertApp returns the app as it was before any update, and whether it was created.
+func upsertApp(client *ApigeeClient, email string, a BulkApp) (*DeveloperApp, bool, BulkAction, error) {
+	existing, resp, e := client.DeveloperApps.Get(email, a.Name)
+	if e != nil {
+		if !isNotFound(resp) {
+			return nil, false, "", fmt.Errorf("while getting app, error: %v", e)
+		}
+		created, _, e := client.DeveloperApps.Create(email, DeveloperApp{Name: a.Name, CallbackUrl: a.CallbackUrl, Attributes: a.Attributes})
+		if e != nil {
+			return nil, false, "", fmt.Errorf("while creating app, error: %v", e)
+		}
+		return created, true, BulkCreated, nil
+	}
+	// Edge replaces the whole app on update, so start from the app as it is and
+	// change only the fields the import manages. Keys are not changed by an app
+	// update; upsertCredential imports them.
+	update := *existing
+	update.Credentials = nil
+	changed := setIfChanged(&update.CallbackUrl, a.CallbackUrl)
+	attrs, attrsChanged := mergeAttributes(existing.Attributes, a.Attributes)
+	if !changed && !attrsChanged {
+		return existing, false, BulkUnchanged, nil
+	}
+	update.Attributes = attrs
+	if _, _, e := client.DeveloperApps.Update(email, update); e != nil {
+		return nil, false, "", fmt.Errorf("while updating app, error: %v", e)
+	}
+	return existing, false, BulkUpdated, nil
+}
+
+// sameExpiry reports whether the credential expires at expiresAt, to the
+// millisecond that Edge keeps.
+func sameExpiry(c *Credential, expiresAt time.Time) bool {
+	return c.Expires() && c.ExpiresAt.Time.Equal(expiresAt.Truncate(time.Millisecond))
+}
+
+func upsertCredential(client *ApigeeClient, email string, app *DeveloperApp, c BulkCredential) (BulkAction, error) {
+	var existing *Credential
+	for i := range app.Credentials {
+		if c.ConsumerKey == "" || app.Credentials[i].ConsumerKey == c.ConsumerKey {
+			existing = &app.Credentials[i]
+			break
+		}
+	}
+	action := BulkUnchanged
+	switch {
+	case existing == nil && c.ConsumerKey == "":
+		return "", errors.New("the app has no key")
+	case existing == nil && c.ExpiresAt != nil:
+		return "", errors.New("cannot import a key with an expiry; Edge sets expiry only on keys it generates")
+	case existing != nil && c.ExpiresAt != nil && !sameExpiry(existing, *c.ExpiresAt):
+		return "", errors.New("the key exists with a different expiry, which Edge cannot change")
+	case existing == nil && c.ConsumerSecret == "":
+		return "", errors.New("cannot import a consumer key without its secret")
+	case existing == nil:
+		created, _, e := client.DeveloperAppCredentials.Create(email, app.Name, Credential{ConsumerKey: c.ConsumerKey, ConsumerSecret: c.ConsumerSecret})
+		if e != nil {
+			return "", fmt.Errorf("while creating key, error: %v", e)
+		}
+		existing = created
+		action = BulkCreated
+	case c.ConsumerSecret != "" && c.ConsumerSecret != existing.ConsumerSecret:
+		return "", errors.New("the key exists with a different secret")
+	}
+	key := existing.ConsumerKey
+	changed := func() {
+		if action == BulkUnchanged {
+			action = BulkUpdated
+		}
+	}
+
+	productStatus := func() map[string]string {
+		status := map[string]string{}
+		for _, p := range existing.ApiProducts {
+			status[p.ApiProduct] = p.Status
+		}
+		return status
+	}
+	current := productStatus()
+	missing := []string{}
+	for _, p := range c.ApiProducts {
+		if _, ok := current[p.ApiProduct]; !ok {
+			missing = addString(missing, p.ApiProduct)
+		}
+	}
+	if len(missing) > 0 {
+		updated, _, e := client.DeveloperAppCredentials.AddApiProducts(email, app.Name, key, missing)
+		if e != nil {
+			return "", fmt.Errorf("while adding products to key, error: %v", e)
+		}
+		existing.ApiProducts = updated.ApiProducts
+		current = productStatus()
+		changed()
+	}
+	for _, p := range c.ApiProducts {
+		if p.Status == "" || p.Status == current[p.ApiProduct] {
+			continue
+		}
+		var e error
+		switch p.Status {
+		case "approved":
+			_, e = client.DeveloperAppCredentials.ApproveApiProduct(email, app.Name, key, p.ApiProduct)
+		case "revoked":
+			_, e = client.DeveloperAppCredentials.RevokeApiProduct(email, app.Name, key, p.ApiProduct)
+		default:
+			e = fmt.Errorf("cannot return a %s product to pending", current[p.ApiProduct])
+		}
+		if e != nil {
+			return "", fmt.Errorf("while setting status of %s on key, error: %v", p.ApiProduct, e)
+		}
+		changed()
+	}
+	if attrs, attrsChanged := mergeAttributes(existing.Attributes, c.Attributes); attrsChanged {
+		if _, _, e := client.Attributes.Replace(DeveloperAppKeyAttributes(email, app.Name, key), attrs); e != nil {
+			return "", fmt.Errorf("while setting key attributes, error: %v", e)
+		}
+		changed()
+	}
+	if c.Status != "" && c.Status != existing.Status {
+		var e error
+		if c.Status == "revoked" {
+			_, e = client.DeveloperAppCredentials.Revoke(email, app.Name, key)
+		} else {
+			_, e = client.DeveloperAppCredentials.Approve(email, app.Name, key)
+		}
+		if e != nil {
+			return "", fmt.Errorf("while setting key status, error: %v", e)
+		}
+		changed()
+	}
+	return action, nil
+}
+
+// Export returns every developer in the organization with its apps and their
+// credentials, including consumer secrets, sorted by email. The result can be
+// written with WriteDevelopersJSON or WriteDevelopersCSV, and imported into
+// another organization with Import.
+func (s *DevelopersServiceOp) Export(opts *BulkOptions) ([]BulkDeveloper, error) {
+	if opts == nil {
+		opts = &BulkOptions{}
+	}
+	developers, _, e := s.ListExpanded()
+	if e != nil {
+		return nil, fmt.Errorf("while listing developers, error: %v", e)
+	}
+	sort.Slice(developers, func(i, j int) bool { return developers[i].Email < developers[j].Email })
+	exported := make([]BulkDeveloper, len(developers))
+	failed := runParallel(len(developers), opts.Parallelism, func(i int) error {
+		apps, _, e := s.GetApps(developers[i].Email)
+		if e != nil {
+			return fmt.Errorf("while getting apps of %s, error: %v", developers[i].Email, e)
+		}
+		exported[i] = bulkDeveloperFrom(developers[i], apps)
+		return nil
+	})
+	for i := range developers {
+		if e, ok := failed[i]; ok {
+			return nil, e
+		}
+	}
+	return exported, nil
+}
+
+func bulkDeveloperFrom(d Developer, apps []DeveloperApp) BulkDeveloper {
+	sort.Slice(apps, func(i, j int) bool { return apps[i].Name < apps[j].Name })
+	exported := BulkDeveloper{Email: d.Email, FirstName: d.FirstName, LastName: d.LastName, UserName: d.UserName, Attributes: d.Attributes}
+	for _, app := range apps {
+		a := BulkApp{Name: app.Name, CallbackUrl: app.CallbackUrl, Attributes: app.Attributes}
+		for _, c := range app.Credentials {
+			credential := BulkCredential{
+				ConsumerKey:    c.ConsumerKey,
+				ConsumerSecret: c.ConsumerSecret,
+				ApiProducts:    c.ApiProducts,
+				Status:         c.Status,
+				Attributes:     c.Attributes,
+			}
+			if c.Expires() {
+				expiresAt := c.ExpiresAt.Time
+				credential.ExpiresAt = &expiresAt
+			}
+			a.Credentials = append(a.Credentials, credential)
+		}
+		exported.Apps = append(exported.Apps, a)
+	}
+	return exported
+}
+
+// ReadDevelopersJSON reads developer records written by WriteDevelopersJSON.
+func ReadDevelopersJSON(r io.Reader) ([]BulkDeveloper, error) {
+	developers := []BulkDeveloper{}
+	if e := json.NewDecoder(r).Decode(&developers); e != nil {
+		return nil, e
+	}
+	return developers, nil
+}
+
+// WriteDevelopersJSON writes developer records as an indented JSON array.
+func WriteDevelopersJSON(w io.Writer, developers []BulkDeveloper) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(developers)
+}
+
+// The columns of the CSV format, in the order WriteDevelopersCSV writes them.
+var bulkCSVColumns = []string{
+	"email", "firstName", "lastName", "userName", "developerAttributes",
+	"appName", "callbackUrl", "appAttributes",
+	"consumerKey", "consumerSecret", "apiProducts", "keyStatus", "expiresAt", "keyAttributes",
+}
+
+// WriteDevelopersCSV writes developer records as CSV with a header row and one
+// row per credential. A developer without apps, or an app without credentials,
+// gets a row of its own with the remaining columns empty. The developer and app
+// columns are repeated on each row. Products are separated by spaces, each
+// followed by its status if it has one, as in "gold:approved silver:pending".
+// Expiry times are in RFC 3339 format, and attributes are written like a URL
+// query string, as in "tier=gold&region=eu".
+func WriteDevelopersCSV(w io.Writer, developers []BulkDeveloper) error {
+	cw := csv.NewWriter(w)
+	if e := cw.Write(bulkCSVColumns); e != nil {
+		return e
+	}
+	for _, d := range developers {
+		developer := []string{d.Email, d.FirstName, d.LastName, d.UserName, encodeAttributes(d.Attributes)}
+		rows := [][]string{}
+		if len(d.Apps) == 0 {
+			rows = append(rows, developer)
+		}
+		for _, a := range d.Apps {
+			app := append(developer[:len(developer):len(developer)], a.Name, a.CallbackUrl, encodeAttributes(a.Attributes))
+			if len(a.Credentials) == 0 {
+				rows = append(rows, app)
+			}
+			for _, c := range a.Credentials {
+				expiresAt := ""
+				if c.ExpiresAt != nil {
+					expiresAt = c.ExpiresAt.UTC().Format(time.RFC3339)
+				}
+				rows = append(rows, append(app[:len(app):len(app)],
+					c.ConsumerKey, c.ConsumerSecret, encodeApiProducts(c.ApiProducts), c.Status, expiresAt, encodeAttributes(c.Attributes)))
+			}
+		}
+		for _, row := range rows {
+			row = append(row, make([]string, len(bulkCSVColumns)-len(row))...)
+			if e := cw.Write(row); e != nil {
+				return e
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadDevelopersCSV reads developer records from CSV in the format written by
+// WriteDevelopersCSV. The header row names the columns, which may come in any
+// order; only email is required. Rows for the same developer, app or consumer
+// key are combined, so a spreadsheet may list each developer once, or repeat it
+// on a row for each app.
+func ReadDevelopersCSV(r io.Reader) ([]BulkDeveloper, error) {
+	cr := csv.NewReader(r)
+	header, e := cr.Read()
+	if e == io.EOF {
+		return nil, errors.New("missing header row")
+	}
+	if e != nil {
+		return nil, e
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		known := false
+		for _, c := range bulkCSVColumns {
+			known = known || c == name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, errors.New("missing email column")
+	}
+
+	developers := []BulkDeveloper{}
+	byEmail := map[string]int{}
+	for row := 2; ; row++ {
+		record, e := cr.Read()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			return nil, e
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		attributes := func(name string, a *Attributes) error {
+			decoded, e := decodeAttributes(field(name))
+			if e != nil {
+				return fmt.Errorf("row %d: %s: %v", row, name, e)
+			}
+			for _, attr := range decoded {
+				a.Set(attr.Name, attr.Value)
+			}
+			return nil
+		}
+
+		email := field("email")
+		if email == "" {
+			return nil, fmt.Errorf("row %d: missing email", row)
+		}
+		i, ok := byEmail[strings.ToLower(email)]
+		if !ok {
+			i = len(developers)
+			byEmail[strings.ToLower(email)] = i
+			developers = append(developers, BulkDeveloper{Email: email})
+		}
+		d := &developers[i]
+		d.Rows = append(d.Rows, row)
+		setIfChanged(&d.FirstName, field("firstName"))
+		setIfChanged(&d.LastName, field("lastName"))
+		setIfChanged(&d.UserName, field("userName"))
+		if e := attributes("developerAttributes", &d.Attributes); e != nil {
+			return nil, e
+		}
+
+		appName := field("appName")
+		if appName == "" {
+			for _, name := range bulkCSVColumns[6:] {
+				if field(name) != "" {
+					return nil, fmt.Errorf("row %d: %s without appName", row, name)
+				}
+			}
+			continue
+		}
+		var a *BulkApp
+		for j := range d.Apps {
+			if d.Apps[j].Name == appName {
+				a = &d.Apps[j]
+			}
+		}
+		if a == nil {
+			d.Apps = append(d.Apps, BulkApp{Name: appName})
+			a = &d.Apps[len(d.Apps)-1]
+		}
+		a.Rows = append(a.Rows, row)
+		setIfChanged(&a.CallbackUrl, field("callbackUrl"))
+		if e := attributes("appAttributes", &a.Attributes); e != nil {
+			return nil, e
+		}
+
+		empty := true
+		for _, name := range bulkCSVColumns[8:] {
+			empty = empty && field(name) == ""
+		}
+		if empty {
+			continue
+		}
+		key := field("consumerKey")
+		var c *BulkCredential
+		for j := range a.Credentials {
+			if a.Credentials[j].ConsumerKey == key {
+				c = &a.Credentials[j]
+			}
+		}
+		if c == nil {
+			a.Credentials = append(a.Credentials, BulkCredential{ConsumerKey: key})
+			c = &a.Credentials[len(a.Credentials)-1]
+		}
+		c.Rows = append(c.Rows, row)
+		setIfChanged(&c.ConsumerSecret, field("consumerSecret"))
+		setIfChanged(&c.Status, field("keyStatus"))
+		if expiresAt := field("expiresAt"); expiresAt != "" {
+			t, e := time.Parse(time.RFC3339, expiresAt)
+			if e != nil {
+				return nil, fmt.Errorf("row %d: expiresAt: %v", row, e)
+			}
+			c.ExpiresAt = &t
+		}
+		for _, p := range decodeApiProducts(field("apiProducts")) {
+			found := false
+			for j := range c.ApiProducts {
+				if c.ApiProducts[j].ApiProduct == p.ApiProduct {
+					found = true
+					setIfChanged(&c.ApiProducts[j].Status, p.Status)
+				}
+			}
+			if !found {
+				c.ApiProducts = append(c.ApiProducts, p)
+			}
+		}
+		if e := attributes("keyAttributes", &c.Attributes); e != nil {
+			return nil, e
+		}
+	}
+	return developers, nil
+}
+
+// encodeApiProducts writes the products of a credential separated by spaces,
+// each followed by a colon and its status if it has one.
+func encodeApiProducts(products []CredentialApiProduct) string {
+	parts := []string{}
+	for _, p := range products {
+		if p.Status == "" {
+			parts = append(parts, p.ApiProduct)
+			continue
+		}
+		parts = append(parts, p.ApiProduct+":"+p.Status)
+	}
+	return strings.Join(parts, " ")
+}
+
+// decodeApiProducts reads products written by encodeApiProducts.
+func decodeApiProducts(s string) []CredentialApiProduct {
+	products := []CredentialApiProduct{}
+	for _, part := range strings.Fields(s) {
+		p := CredentialApiProduct{ApiProduct: part}
+		if i := strings.LastIndex(part, ":"); i > 0 {
+			p = CredentialApiProduct{ApiProduct: part[:i], Status: part[i+1:]}
+		}
+		products = append(products, p)
+	}
+	return products
+}
+
+// encodeAttributes writes attributes like a URL query string, keeping their order.
+func encodeAttributes(a Attributes) string {
+	parts := []string{}
+	for _, attr := range a {
+		parts = append(parts, url.QueryEscape(attr.Name)+"="+url.QueryEscape(attr.Value))
+	}
+	return strings.Join(parts, "&")
+}
+
+// decodeAttributes reads attributes written by encodeAttributes.
+func decodeAttributes(s string) (Attributes, error) {
+	if s == "" {
+		return nil, nil
+	}
+	a := Attributes{}
+	for _, part := range strings.Split(s, "&") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%q is not name=value", part)
+		}
+		name, e := url.QueryUnescape(kv[0])
+		if e != nil {
+			return nil, e
+		}
+		value, e := url.QueryUnescape(kv[1])
+		if e != nil {
+			return nil, e
+		}
+		a.Set(name, value)
+	}
+	return a, nil
+}
@@ -0,0 +1,233 @@
+package apigee
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDevelopersCSV(t *testing.T) {
+	expiresAt := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	developers := []BulkDeveloper{
+		{Email: "a@example.com", FirstName: "A", LastName: "Person", UserName: "a", Rows: []int{2, 3, 4},
+			Attributes: Attributes{{"tier", "gold"}, {"note", "x=1&y"}},
+			Apps: []BulkApp{
+				{Name: "one", CallbackUrl: "https://example.com/cb", Rows: []int{2, 3}, Credentials: []BulkCredential{
+					{ConsumerKey: "k1", ConsumerSecret: "s1", ApiProducts: []CredentialApiProduct{{"p1", "approved"}, {"p2", "pending"}}, Status: "approved", ExpiresAt: &expiresAt, Rows: []int{2}},
+					{ConsumerKey: "k2", ConsumerSecret: "s2", Status: "revoked", Attributes: Attributes{{"rotated", "true"}}, Rows: []int{3}},
+				}},
+				{Name: "two", Attributes: Attributes{{"env", "test"}}, Rows: []int{4}},
+			}},
+		{Email: "b@example.com", FirstName: "B", LastName: "Person", UserName: "b", Rows: []int{5}},
+	}
+	buf := new(bytes.Buffer)
+	if e := WriteDevelopersCSV(buf, developers); e != nil {
+		t.Fatalf("while writing CSV, error: %v", e)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Errorf("rows: got=%q", lines)
+	}
+	read, e := ReadDevelopersCSV(buf)
+	if e != nil {
+		t.Fatalf("while reading CSV, error: %v", e)
+	}
+	if !reflect.DeepEqual(read, developers) {
+		t.Errorf("round trip: got=%+v, expected=%+v", read, developers)
+	}
+}
+
+func TestReadDevelopersCSV(t *testing.T) {
+	tests := []struct {
+		desc     string
+		input    string
+		expected []BulkDeveloper
+		err      string
+	}{
+		{
+			desc:  "columns in any order, rows combined",
+			input: "appName,email,apiProducts,consumerKey\none,a@example.com,p1,\none,A@example.com,p2 p1:revoked,\ntwo,a@example.com,,\n,b@example.com,,\n",
+			expected: []BulkDeveloper{
+				{Email: "a@example.com", Rows: []int{2, 3, 4}, Apps: []BulkApp{
+					{Name: "one", Rows: []int{2, 3}, Credentials: []BulkCredential{{ApiProducts: []CredentialApiProduct{{"p1", "revoked"}, {"p2", ""}}, Rows: []int{2, 3}}}},
+					{Name: "two", Rows: []int{4}},
+				}},
+				{Email: "b@example.com", Rows: []int{5}},
+			},
+		},
+		{desc: "unknown column", input: "email,phone\n", err: `unknown column "phone"`},
+		{desc: "no email column", input: "appName\none\n", err: "missing email column"},
+		{desc: "missing email", input: "email,appName\n,one\n", err: "row 2: missing email"},
+		{desc: "key without app", input: "email,consumerKey\na@example.com,k1\n", err: "row 2: consumerKey without appName"},
+		{desc: "bad expiry", input: "email,appName,expiresAt\na@example.com,one,tomorrow\n", err: `row 2: expiresAt: parsing time "tomorrow" as "2006-01-02T15:04:05Z07:00": cannot parse "tomorrow" as "2006"`},
+		{desc: "bad attributes", input: "email,developerAttributes\na@example.com,tier\n", err: `row 2: developerAttributes: "tier" is not name=value`},
+	}
+	for _, test := range tests {
+		got, e := ReadDevelopersCSV(strings.NewReader(test.input))
+		if test.err != "" {
+			if e == nil || e.Error() != test.err {
+				t.Errorf("%s: got error=%v, expected=%s", test.desc, e, test.err)
+			}
+			continue
+		}
+		if e != nil {
+			t.Errorf("%s: error: %v", test.desc, e)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: got=%+v, expected=%+v", test.desc, got, test.expected)
+		}
+	}
+}
+
+func TestDevelopersJSON(t *testing.T) {
+	developers := []BulkDeveloper{{Email: "a@example.com", Apps: []BulkApp{{Name: "one", Credentials: []BulkCredential{{ConsumerKey: "k1", ConsumerSecret: "s1"}}}}}}
+	buf := new(bytes.Buffer)
+	if e := WriteDevelopersJSON(buf, developers); e != nil {
+		t.Fatalf("while writing JSON, error: %v", e)
+	}
+	read, e := ReadDevelopersJSON(buf)
+	if e != nil {
+		t.Fatalf("while reading JSON, error: %v", e)
+	}
+	if !reflect.DeepEqual(read, developers) {
+		t.Errorf("round trip: got=%+v, expected=%+v", read, developers)
+	}
+}
+
+func TestValidateBulkDevelopers(t *testing.T) {
+	app := func(credentials ...BulkCredential) BulkApp { return BulkApp{Name: "one", Credentials: credentials} }
+	tests := []struct {
+		desc       string
+		developers []BulkDeveloper
+		err        string
+	}{
+		{"valid", []BulkDeveloper{{Email: "a@example.com", Apps: []BulkApp{app(BulkCredential{}, BulkCredential{ConsumerKey: "k1"})}}}, ""},
+		{"no email", []BulkDeveloper{{}}, "a developer has no email"},
+		{"duplicate developer", []BulkDeveloper{{Email: "a@example.com"}, {Email: "A@example.com"}}, "developer A@example.com is listed more than once"},
+		{"duplicate app", []BulkDeveloper{{Email: "a@example.com", Apps: []BulkApp{app(), app()}}}, "app one of developer a@example.com is listed more than once"},
+		{"duplicate key", []BulkDeveloper{
+			{Email: "a@example.com", Apps: []BulkApp{app(BulkCredential{ConsumerKey: "k1"})}},
+			{Email: "b@example.com", Apps: []BulkApp{app(BulkCredential{ConsumerKey: "k1"})}},
+		}, "consumer key k1 is listed more than once"},
+		{"two generated keys", []BulkDeveloper{{Email: "a@example.com", Apps: []BulkApp{app(BulkCredential{}, BulkCredential{})}}},
+			"app one of developer a@example.com has more than one credential without a consumer key"},
+		{"bad status", []BulkDeveloper{{Email: "a@example.com", Apps: []BulkApp{app(BulkCredential{Status: "pending"})}}},
+			`app one of developer a@example.com: unknown key status "pending"`},
+		{"bad product status", []BulkDeveloper{{Email: "a@example.com", Apps: []BulkApp{app(BulkCredential{ApiProducts: []CredentialApiProduct{{"p1", "ok"}}})}}},
+			`app one of developer a@example.com: unknown status "ok" for API product p1`},
+	}
+	for _, test := range tests {
+		e := validateBulkDevelopers(test.developers)
+		got := ""
+		if e != nil {
+			got = e.Error()
+		}
+		if got != test.err {
+			t.Errorf("%s: got=%q, expected=%q", test.desc, got, test.err)
+		}
+	}
+}
+
+func TestBulkDeveloperFrom(t *testing.T) {
+	expiresAt := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	apps := []DeveloperApp{
+		{Name: "two"},
+		{Name: "one", Credentials: []Credential{
+			{ConsumerKey: "k1", ConsumerSecret: "s1", Status: "approved", ExpiresAt: Timestamp{expiresAt},
+				ApiProducts: []CredentialApiProduct{{"p1", "pending"}}},
+			{ConsumerKey: "k2", ConsumerSecret: "s2", Status: "approved", ExpiresAt: Timestamp{time.Unix(0, -int64(time.Millisecond))}},
+		}},
+	}
+	got := bulkDeveloperFrom(Developer{Email: "a@example.com"}, apps)
+	expected := BulkDeveloper{Email: "a@example.com", Apps: []BulkApp{
+		{Name: "one", Credentials: []BulkCredential{
+			{ConsumerKey: "k1", ConsumerSecret: "s1", Status: "approved", ExpiresAt: &expiresAt,
+				ApiProducts: []CredentialApiProduct{{"p1", "pending"}}},
+			{ConsumerKey: "k2", ConsumerSecret: "s2", Status: "approved"},
+		}},
+		{Name: "two"},
+	}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got=%+v, expected=%+v", got, expected)
+	}
+}
+
+func TestSkippedApp(t *testing.T) {
+	a := BulkApp{Name: "one", Rows: []int{2, 3}, Credentials: []BulkCredential{{ConsumerKey: "k1", Rows: []int{3}}}}
+	got := skippedApp("a@example.com", a)
+	expected := []BulkResult{
+		{Developer: "a@example.com", App: "one", Rows: []int{2, 3}, Action: BulkSkipped},
+		{Developer: "a@example.com", App: "one", ConsumerKey: "k1", Rows: []int{3}, Action: BulkSkipped},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got=%+v, expected=%+v", got, expected)
+	}
+}
+
+func TestMergeAttributes(t *testing.T) {
+	existing := Attributes{{"a", "1"}, {"b", "2"}}
+	merged, changed := mergeAttributes(existing, Attributes{{"b", "2"}})
+	if changed || !reflect.DeepEqual(merged, existing) {
+		t.Errorf("same value: got=%v, changed=%v", merged, changed)
+	}
+	merged, changed = mergeAttributes(existing, Attributes{{"b", "3"}, {"c", "4"}})
+	expected := Attributes{{"a", "1"}, {"b", "3"}, {"c", "4"}}
+	if !changed || !reflect.DeepEqual(merged, expected) {
+		t.Errorf("new values: got=%v, changed=%v", merged, changed)
+	}
+	if v, _ := existing.Get("b"); v != "2" {
+		t.Errorf("existing was modified: got=%v", existing)
+	}
+}
+
+func TestImportDevelopers(t *testing.T) {
+	client := NewClientForTesting(t)
+	dev, e := randomDeveloperFromTemplate()
+	if e != nil {
+		t.Fatalf("while creating developer from template, error: %v", e)
+	}
+	key := testPrefix + randomString(24)
+	developers := []BulkDeveloper{{
+		Email: dev.Email, FirstName: dev.FirstName, LastName: dev.LastName, UserName: dev.UserName,
+		Attributes: Attributes{{"source", "bulk"}},
+		Apps: []BulkApp{{Name: testPrefix + randomString(7), Credentials: []BulkCredential{
+			{ConsumerKey: key, ConsumerSecret: randomString(16), Status: "revoked"},
+		}}},
+	}}
+	defer client.Developers.Delete(dev.Email)
+
+	expectActions := func(desc string, results []BulkResult, expected ...BulkAction) {
+		got := []BulkAction{}
+		for _, r := range results {
+			got = append(got, r.Action)
+			if r.Error != "" {
+				t.Errorf("%s: %s/%s/%s: %s", desc, r.Developer, r.App, r.ConsumerKey, r.Error)
+			}
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: got=%v, expected=%v", desc, got, expected)
+		}
+	}
+	results, e := client.Developers.Import(developers, nil)
+	if e != nil {
+		t.Fatalf("while importing, error: %v", e)
+	}
+	expectActions("first import", results, BulkCreated, BulkCreated, BulkCreated)
+
+	app, _, e := client.DeveloperApps.Get(dev.Email, developers[0].Apps[0].Name)
+	if e != nil {
+		t.Fatalf("while getting app, error: %v", e)
+	}
+	if len(app.Credentials) != 1 || app.Credentials[0].ConsumerKey != key || app.Credentials[0].Status != "revoked" {
+		t.Errorf("credentials: got=%+v", app.Credentials)
+	}
+
+	results, e = client.Developers.Import(developers, nil)
+	if e != nil {
+		t.Fatalf("while importing again, error: %v", e)
+	}
+	expectActions("second import", results, BulkUnchanged, BulkUnchanged, BulkUnchanged)
+}
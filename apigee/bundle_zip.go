@@ -0,0 +1,307 @@
+package apigee
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/brayanhenao/go-apigee-edge/bundle"
+)
+
+const defaultIgnoreFile = ".apigeeignore"
+
+// ImportOptions holds optional parameters for importing an API Proxy or
+// SharedFlow. IgnoreFile and Filters apply only when importing from a directory.
+type ImportOptions struct {
+	// Optional. When true, Import first looks for a recent revision with the
+	// same content as the bundle, and returns that revision instead of creating
+	// a new one. Content is compared by fingerprint; see bundle.Bundle.Fingerprint.
+	// When no name is given for the import, the name in the bundle descriptor
+	// is used to find the revisions.
+	SkipUnchanged bool
+
+	// Optional. The number of most recent revisions to examine when
+	// SkipUnchanged is set. Defaults to 5.
+	RecentRevisions int
+
+	// Optional. When true, the fingerprint of the bundle is appended to the
+	// description of the imported revision, so that later imports using
+	// SkipUnchanged can match it without exporting the revision.
+	RecordFingerprint bool
+
+	// Optional. The name of a file within the source directory that lists
+	// patterns of files to leave out of the bundle, one per line, in the style
+	// of .gitignore. Defaults to ".apigeeignore". It is not an error for the
+	// file to be absent.
+	IgnoreFile string
+
+	// Optional. Specializes the bundle before it is imported, for example
+	// setting the target URL or quota for an environment. Applies to zip files
+	// as well as directories.
+	Template *bundle.Template
+
+	// Optional. Additional filters. A file or directory is included in the
+	// bundle only if every filter returns true. Each filter is passed a
+	// slash-separated path relative to the source directory, like
+	// "apiproxy/resources/jsc/hello.js". Directories have a trailing slash.
+	Filters []func(string) bool
+}
+
+// smartFilter excludes editor backup files and the clutter that version
+// control systems and desktop file managers leave behind.
+func smartFilter(path string) bool {
+	name := strings.TrimSuffix(path, "/")
+	name = name[strings.LastIndex(name, "/")+1:]
+	if strings.HasSuffix(name, "~") {
+		return false
+	}
+	if strings.HasSuffix(name, "#") && strings.HasPrefix(name, "#") {
+		return false
+	}
+	switch name {
+	case ".git", ".svn", ".hg", ".DS_Store", "Thumbs.db", defaultIgnoreFile:
+		return false
+	}
+	return true
+}
+
+// ignorePattern is one line of an ignore file.
+type ignorePattern struct {
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// readIgnoreFile reads patterns from the named file. A missing file yields no patterns.
+func readIgnoreFile(filename string) ([]ignorePattern, error) {
+	file, e := os.Open(filename)
+	if os.IsNotExist(e) {
+		return nil, nil
+	}
+	if e != nil {
+		return nil, e
+	}
+	defer file.Close()
+	return parseIgnorePatterns(file)
+}
+
+func parseIgnorePatterns(r io.Reader) ([]ignorePattern, error) {
+	patterns := []ignorePattern{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p := ignorePattern{}
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.Contains(strings.TrimPrefix(line, "**/"), "/") {
+			p.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		} else {
+			line = strings.TrimPrefix(line, "**/")
+		}
+		p.pattern = line
+		patterns = append(patterns, p)
+	}
+	return patterns, scanner.Err()
+}
+
+// ignoreFilter returns a filter that excludes paths matching the patterns.
+// As with .gitignore, the last matching pattern wins, so a pattern starting
+// with "!" can re-include a path excluded by an earlier pattern. A pattern
+// containing a slash is matched against the whole path, where "**" matches any
+// number of path elements; any other pattern is matched against each path
+// element.
+func ignoreFilter(patterns []ignorePattern) func(string) bool {
+	return func(p string) bool {
+		isDir := strings.HasSuffix(p, "/")
+		p = strings.TrimSuffix(p, "/")
+		included := true
+		for _, pattern := range patterns {
+			if pattern.dirOnly && !isDir {
+				continue
+			}
+			if pattern.matches(p) {
+				included = pattern.negate
+			}
+		}
+		return included
+	}
+}
+
+func (ip ignorePattern) matches(p string) bool {
+	if ip.anchored {
+		return matchSegments(strings.Split(ip.pattern, "/"), strings.Split(p, "/"))
+	}
+	matched, _ := path.Match(ip.pattern, path.Base(p))
+	return matched
+}
+
+// matchSegments matches path elements against pattern elements, each of which
+// is matched with path.Match, except that "**" matches zero or more elements.
+func matchSegments(pattern, elements []string) bool {
+	if len(pattern) == 0 {
+		return len(elements) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(elements); i++ {
+			if matchSegments(pattern[1:], elements[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(elements) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], elements[0]); !matched {
+		return false
+	}
+	return matchSegments(pattern[1:], elements[1:])
+}
+
+// bundleFilter combines the default filter, the ignore file in the source
+// directory and any caller-supplied filters.
+func bundleFilter(source string, opts *ImportOptions) (func(string) bool, error) {
+	filters := []func(string) bool{smartFilter}
+	ignoreFile := defaultIgnoreFile
+	if opts != nil && opts.IgnoreFile != "" {
+		ignoreFile = opts.IgnoreFile
+	}
+	patterns, e := readIgnoreFile(filepath.Join(source, ignoreFile))
+	if e != nil {
+		return nil, e
+	}
+	if len(patterns) > 0 {
+		filters = append(filters, ignoreFilter(patterns))
+	}
+	if opts != nil {
+		filters = append(filters, opts.Filters...)
+	}
+	return func(p string) bool {
+		for _, filter := range filters {
+			if !filter(p) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// ZipBundle zips the exploded apiproxy or sharedflowbundle directory found
+// within source into target, exactly as Import does. The zip is reproducible:
+// entries are sorted, and timestamps and permissions are fixed, so identical
+// sources produce byte-identical zips that can be hashed.
+func ZipBundle(source, target string, opts *ImportOptions) error {
+	filePathElement := "apiproxy"
+	if info, e := os.Stat(filepath.Join(source, filePathElement)); e != nil || !info.IsDir() {
+		filePathElement = "sharedflowbundle"
+	}
+	filter, e := bundleFilter(source, opts)
+	if e != nil {
+		return e
+	}
+	if e := zipDirectory(filepath.Join(source, filePathElement), target, filter); e != nil {
+		return e
+	}
+	if opts == nil || opts.Template == nil {
+		return nil
+	}
+	templatedName, cleanup, e := templateZip(target, opts.Template)
+	if e != nil {
+		return e
+	}
+	defer cleanup()
+	content, e := ioutil.ReadFile(templatedName)
+	if e != nil {
+		return e
+	}
+	return ioutil.WriteFile(target, content, 0644)
+}
+
+// zipDirectory zips source, which must be a directory, into target, with
+// bundle.WriteZipEntries. The filter is passed the name each entry would have
+// in the zip, eg "apiproxy/policies/", and a directory that is filtered out is
+// skipped entirely.
+func zipDirectory(source string, target string, filter func(string) bool) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return &os.PathError{Op: "zip", Path: source, Err: os.ErrInvalid}
+	}
+	baseDir := filepath.Base(source)
+
+	entries := []bundle.ZipEntry{}
+	err = filepath.Walk(source, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(source, p)
+		if err != nil {
+			return err
+		}
+		name := path.Join(baseDir, filepath.ToSlash(rel))
+		if info.IsDir() {
+			name += "/"
+		}
+		if filter != nil && !filter(name) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		entry := bundle.ZipEntry{Name: name}
+		if !info.IsDir() {
+			if entry.Content, err = ioutil.ReadFile(p); err != nil {
+				return err
+			}
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	zipfile, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if err := bundle.WriteZipEntries(zipfile, entries); err != nil {
+		zipfile.Close()
+		return err
+	}
+	return zipfile.Close()
+}
+
+// templateZip writes a copy of the zip with the template applied. It returns
+// the name of the new zip and a function that removes it.
+func templateZip(zipfileName string, t *bundle.Template) (string, func(), error) {
+	b, e := bundle.Load(zipfileName)
+	if e != nil {
+		return "", nil, e
+	}
+	b, e = b.ApplyTemplate(t)
+	if e != nil {
+		return "", nil, fmt.Errorf("while applying template, error: %v", e)
+	}
+	return writeTempZip(b)
+}
@@ -0,0 +1,191 @@
+package apigee
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brayanhenao/go-apigee-edge/bundle"
+)
+
+const ignoreFile1 = `
+# comments and blank lines are skipped
+node_modules/
+*.log
+/apiproxy/resources/jsc/test/
+apiproxy/resources/**/fixtures/*.js
+**/scratch/*.txt
+!keep.log
+`
+
+func TestIgnoreFilter(t *testing.T) {
+	patterns, e := parseIgnorePatterns(strings.NewReader(ignoreFile1))
+	if e != nil {
+		t.Fatalf("while parsing patterns, error:\n%#v\n", e)
+	}
+	filter := ignoreFilter(patterns)
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"apiproxy/", true},
+		{"apiproxy/policies/AM-Test.xml", true},
+		{"apiproxy/resources/node/node_modules/", false},
+		{"apiproxy/resources/node/node_modules", true}, // a file, not a directory
+		{"apiproxy/debug.log", false},
+		{"apiproxy/resources/keep.log", true},
+		{"apiproxy/resources/jsc/test/", false},
+		{"apiproxy/resources/jsc/other/test/", true},
+		{"apiproxy/resources/fixtures/a.js", false},
+		{"apiproxy/resources/jsc/lib/fixtures/a.js", false},
+		{"apiproxy/resources/jsc/lib/fixtures/a.json", true},
+		{"apiproxy/policies/fixtures/a.js", true},
+		{"apiproxy/scratch/notes.txt", false},
+		{"apiproxy/resources/scratch/notes.txt", false},
+		{"scratch/notes.txt", false},
+	}
+	for _, tc := range testCases {
+		if got := filter(tc.path); got != tc.expected {
+			t.Errorf("%s: got=%v, expected=%v", tc.path, got, tc.expected)
+		}
+	}
+}
+
+func TestSmartFilter(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"apiproxy/policies/AM-Test.xml", true},
+		{"apiproxy/policies/AM-Test.xml~", false},
+		{"apiproxy/policies/#AM-Test.xml#", false},
+		{"apiproxy/.DS_Store", false},
+		{"apiproxy/.git/", false},
+		{"apiproxy/resources/jsc/.gitkeep", true},
+	}
+	for _, tc := range testCases {
+		if got := smartFilter(tc.path); got != tc.expected {
+			t.Errorf("%s: got=%v, expected=%v", tc.path, got, tc.expected)
+		}
+	}
+}
+
+func copyTree(t *testing.T, source, target string) {
+	e := filepath.Walk(source, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(source, p)
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(target, rel), 0755)
+		}
+		content, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(target, rel), content, 0600)
+	})
+	if e != nil {
+		t.Fatalf("while copying %s, error:\n%#v\n", source, e)
+	}
+}
+
+func TestZipBundleIsReproducible(t *testing.T) {
+	tempDir, e := ioutil.TempDir("", "go-apigee-test-")
+	if e != nil {
+		t.Fatalf("while creating temp dir, error:\n%#v\n", e)
+	}
+	defer os.RemoveAll(tempDir)
+
+	source := filepath.Join(tempDir, "library")
+	copyTree(t, filepath.Join(proxyBundleDir, "apiproxy-library"), source)
+	clutter := []string{
+		"apiproxy/.DS_Store",
+		"apiproxy/.git/HEAD",
+		"apiproxy/resources/node/node_modules/left-pad/index.js",
+		"apiproxy/policies/Xml-to-Json.xml~",
+	}
+	for _, name := range clutter {
+		p := filepath.Join(source, filepath.FromSlash(name))
+		_ = os.MkdirAll(filepath.Dir(p), 0755)
+		if e := ioutil.WriteFile(p, []byte("clutter"), 0644); e != nil {
+			t.Fatalf("while writing %s, error:\n%#v\n", name, e)
+		}
+	}
+	if e := ioutil.WriteFile(filepath.Join(source, ".apigeeignore"), []byte("node_modules/\n"), 0644); e != nil {
+		t.Fatalf("while writing ignore file, error:\n%#v\n", e)
+	}
+	opts := &ImportOptions{Filters: []func(string) bool{
+		func(p string) bool { return !strings.HasSuffix(p, "Unknown-Resource.xml") },
+	}}
+
+	first := filepath.Join(tempDir, "first.zip")
+	if e := ZipBundle(source, first, opts); e != nil {
+		t.Fatalf("while zipping, error:\n%#v\n", e)
+	}
+	later := time.Now().Add(time.Hour)
+	_ = os.Chtimes(filepath.Join(source, "apiproxy", "library.xml"), later, later)
+	second := filepath.Join(tempDir, "second.zip")
+	if e := ZipBundle(source, second, opts); e != nil {
+		t.Fatalf("while zipping, error:\n%#v\n", e)
+	}
+
+	a, _ := ioutil.ReadFile(first)
+	b, _ := ioutil.ReadFile(second)
+	if !bytes.Equal(a, b) {
+		t.Errorf("zips of identical sources differ")
+	}
+
+	archive, e := zip.NewReader(bytes.NewReader(a), int64(len(a)))
+	if e != nil {
+		t.Fatalf("while reading zip, error:\n%#v\n", e)
+	}
+	names := []string{}
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+		if f.Modified.Year() != 1980 || !f.Modified.Equal(archive.File[0].Modified) {
+			t.Errorf("%s: modified=%v", f.Name, f.Modified)
+		}
+		for _, excluded := range []string{".DS_Store", ".git", "node_modules", "~", "Unknown-Resource"} {
+			if strings.Contains(f.Name, excluded) {
+				t.Errorf("%s should have been excluded", f.Name)
+			}
+		}
+	}
+	for i := 1; i < len(names); i++ {
+		if names[i-1] > names[i] {
+			t.Errorf("entries out of order: %s, %s", names[i-1], names[i])
+		}
+	}
+	if len(names) == 0 || names[0] != "apiproxy/" {
+		t.Errorf("entries: got=%v", names)
+	}
+}
+
+func TestZipBundleWithTemplate(t *testing.T) {
+	tempDir, e := ioutil.TempDir("", "go-apigee-test-")
+	if e != nil {
+		t.Fatalf("while creating temp dir, error:\n%#v\n", e)
+	}
+	defer os.RemoveAll(tempDir)
+
+	opts := &ImportOptions{Template: &bundle.Template{Replacements: map[string]string{
+		"targets/library-soap.xml:/TargetEndpoint/HTTPTargetConnection/URL": "https://prod.example.com/Library",
+	}}}
+	target := filepath.Join(tempDir, "prod.zip")
+	if e := ZipBundle(filepath.Join(proxyBundleDir, "apiproxy-library"), target, opts); e != nil {
+		t.Fatalf("while zipping, error:\n%#v\n", e)
+	}
+	b, e := bundle.Load(target)
+	if e != nil {
+		t.Fatalf("while loading zip, error:\n%#v\n", e)
+	}
+	if got := b.TargetEndpoint("library-soap").HTTPTargetConnection.URL; got != "https://prod.example.com/Library" {
+		t.Errorf("target URL: got=%s", got)
+	}
+}
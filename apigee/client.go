@@ -26,13 +26,17 @@ type ApigeeClient struct {
 	UserAgent string
 
 	// Services used for communicating with the API
+	Apps                  AppsService
+	Attributes            AttributesService
 	Caches                CachesService
 	Companies             CompaniesService
 	CompanyAppCredentials CompanyAppCredentialsService
 	CompanyApps           CompanyAppsService
+	CompanyDevelopers     CompanyDevelopersService
 	DeveloperApps         DeveloperAppsService
 	Developers            DevelopersService
 	Environments          EnvironmentsService
+	FlowHooks             FlowHooksService
 	KeyValueMapEntries    KeyValueMapEntriesService
 	KeyValueMaps          KeyValueMapsService
 	Options               ApigeeClientOptions
@@ -43,6 +47,8 @@ type ApigeeClient struct {
 	TargetServers         TargetServersService
 	VirtualHosts          VirtualHostsService
 
+	DeveloperAppCredentials DeveloperAppCredentialsService
+
 	// Account           AccountService
 	// Actions           ActionsService
 	// Domains           DomainsService
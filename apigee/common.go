@@ -12,16 +12,11 @@ type CredentialApiProduct struct {
 
 type Credential struct {
 	ApiProducts    []CredentialApiProduct `json:"apiProducts,omitempty"`
-	Attributes     []Attribute            `json:"attributes,omitempty"`
+	Attributes     Attributes             `json:"attributes,omitempty"`
 	ConsumerKey    string                 `json:"consumerKey,omitempty"`
 	ConsumerSecret string                 `json:"consumerSecret,omitempty"`
-	ExpiresAt      int                    `json:"expiresAt,omitempty"`
-	IssuedAt       int                    `json:"issuedAt,omitempty"`
+	ExpiresAt      Timestamp              `json:"expiresAt,omitempty"`
+	IssuedAt       Timestamp              `json:"issuedAt,omitempty"`
 	Scopes         []string               `json:"scopes,omitempty"`
 	Status         string                 `json:"status,omitempty"`
 }
-
-//This is just a placeholder
-type App struct {
-	ApigeeId string
-}
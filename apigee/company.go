@@ -1,6 +1,7 @@
 package apigee
 
 import (
+	"net/url"
 	"path"
 )
 
@@ -9,9 +10,13 @@ const companiesPath = "companies"
 // CompanyService is an interface for interfacing with the Apigee Edge Admin API
 // dealing with companies.
 type CompaniesService interface {
+	Approve(string) (*Response, error)
 	Create(Company) (*Company, *Response, error)
 	Delete(string) (*Response, error)
 	Get(string) (*Company, *Response, error)
+	List() ([]string, *Response, error)
+	ListExpanded() ([]Company, *Response, error)
+	Revoke(string) (*Response, error)
 	Update(Company) (*Company, *Response, error)
 }
 
@@ -22,11 +27,11 @@ type CompaniesServiceOp struct {
 var _ CompaniesService = &CompaniesServiceOp{}
 
 type Company struct {
-	Apps        []string    `json:"apps,omitempty"`
-	Attributes  []Attribute `json:"attributes,omitempty"`
-	DisplayName string      `json:"displayName,omitempty"`
-	Name        string      `json:"name,omitempty"`
-	Status      string      `json:"status,omitempty"`
+	Apps        []string   `json:"apps,omitempty"`
+	Attributes  Attributes `json:"attributes,omitempty"`
+	DisplayName string     `json:"displayName,omitempty"`
+	Name        string     `json:"name,omitempty"`
+	Status      string     `json:"status,omitempty"`
 }
 
 func (s *CompaniesServiceOp) Get(name string) (*Company, *Response, error) {
@@ -101,3 +106,71 @@ func postOrPutCompany(company Company, opType string, s *CompaniesServiceOp) (*C
 	return &returnedCompany, resp, e
 
 }
+
+// List retrieves the names of the companies in the organization.
+func (s *CompaniesServiceOp) List() ([]string, *Response, error) {
+	req, e := s.client.NewRequest("GET", companiesPath, nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	namelist := make([]string, 0)
+	resp, e := s.client.Do(req, &namelist)
+	if e != nil {
+		return nil, resp, e
+	}
+	return namelist, resp, e
+}
+
+// ListExpanded retrieves every company in the organization, with full details.
+func (s *CompaniesServiceOp) ListExpanded() ([]Company, *Response, error) {
+	req, e := s.client.NewRequest("GET", companiesPath+"?expand=true", nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	companies := struct {
+		Companies []Company `json:"company"`
+	}{}
+	resp, e := s.client.Do(req, &companies)
+	if e != nil {
+		return nil, resp, e
+	}
+	if companies.Companies == nil {
+		companies.Companies = []Company{}
+	}
+	return companies.Companies, resp, e
+}
+
+func updateCompanyStatus(s CompaniesServiceOp, companyName string, desiredStatus string) (*Response, error) {
+
+	companyPath := path.Join(companiesPath, companyName)
+
+	// append the necessary query param
+	origURL, e := url.Parse(companyPath)
+	if e != nil {
+		return nil, e
+	}
+	q := origURL.Query()
+	q.Add("action", desiredStatus)
+	origURL.RawQuery = q.Encode()
+	companyPath = origURL.String()
+
+	req, e := s.client.NewRequest("POST", companyPath, nil)
+	if e != nil {
+		return nil, e
+	}
+	resp, e := s.client.Do(req, nil)
+	if e != nil {
+		return resp, e
+	}
+	return resp, e
+}
+
+// Revoke sets the status of a company to inactive. The keys of its apps stop working.
+func (s *CompaniesServiceOp) Revoke(companyName string) (*Response, error) {
+	return updateCompanyStatus(*s, companyName, "inactive")
+}
+
+// Approve sets the status of a company to active.
+func (s *CompaniesServiceOp) Approve(companyName string) (*Response, error) {
+	return updateCompanyStatus(*s, companyName, "active")
+}
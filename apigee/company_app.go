@@ -12,6 +12,8 @@ type CompanyAppsService interface {
 	Create(string, CompanyApp) (*CompanyApp, *Response, error)
 	Delete(string, string) (*Response, error)
 	Get(string, string) (*CompanyApp, *Response, error)
+	List(string) ([]string, *Response, error)
+	ListExpanded(string) ([]CompanyApp, *Response, error)
 	Update(string, CompanyApp) (*CompanyApp, *Response, error)
 }
 
@@ -25,7 +27,7 @@ type CompanyApp struct {
 	ApiProducts []string     `json:"apiProducts,omitempty"`
 	AppFamily   string       `json:"appFamily,omitempty"`
 	AppId       string       `json:"appId,omitempty"`
-	Attributes  []Attribute  `json:"attributes,omitempty"`
+	Attributes  Attributes   `json:"attributes,omitempty"`
 	CallbackUrl string       `json:"callbackUrl,omitempty"`
 	CompanyName string       `json:"companyName,omitempty"`
 	Credentials []Credential `json:"credentials,omitempty"`
@@ -106,3 +108,38 @@ func postOrPutCompanyApp(companyName string, companyApp CompanyApp, opType strin
 	return &returnedCompanyApp, resp, e
 
 }
+
+// List retrieves the names of the apps of a company.
+func (s *CompanyAppsServiceOp) List(companyName string) ([]string, *Response, error) {
+	appsPath := path.Join(companiesPath, companyName, appPath)
+	req, e := s.client.NewRequest("GET", appsPath, nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	nameList := make([]string, 0)
+	resp, e := s.client.Do(req, &nameList)
+	if e != nil {
+		return nil, resp, e
+	}
+	return nameList, resp, e
+}
+
+// ListExpanded retrieves the apps of a company, with their credentials.
+func (s *CompanyAppsServiceOp) ListExpanded(companyName string) ([]CompanyApp, *Response, error) {
+	appsPath := path.Join(companiesPath, companyName, appPath) + "?expand=true"
+	req, e := s.client.NewRequest("GET", appsPath, nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	apps := struct {
+		Apps []CompanyApp `json:"app"`
+	}{}
+	resp, e := s.client.Do(req, &apps)
+	if e != nil {
+		return nil, resp, e
+	}
+	if apps.Apps == nil {
+		apps.Apps = []CompanyApp{}
+	}
+	return apps.Apps, resp, e
+}
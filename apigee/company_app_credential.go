@@ -1,7 +1,11 @@
 package apigee
 
 import (
+	"fmt"
+	"net/url"
 	"path"
+	"strconv"
+	"time"
 )
 
 const keysPath = "keys"
@@ -9,10 +13,17 @@ const keysPath = "keys"
 // CompanyAppCredentialsService is an interface for interfacing with the Apigee Edge Admin API
 // dealing with companyApp credentials/keys.
 type CompanyAppCredentialsService interface {
+	AddApiProducts(string, string, string, []string) (*Credential, *Response, error)
+	Approve(string, string, string) (*Response, error)
+	ApproveApiProduct(string, string, string, string) (*Response, error)
 	Create(string, string, Credential) (*Credential, *Response, error)
 	Delete(string, string, string) (*Response, error)
+	Generate(string, string, []string, time.Duration) (*Credential, *Response, error)
 	Get(string, string, string) (*Credential, *Response, error)
 	RemoveApiProduct(string, string, string, string) (*Response, error)
+	Revoke(string, string, string) (*Response, error)
+	RevokeApiProduct(string, string, string, string) (*Response, error)
+	Rotate(string, string, string, *KeyRotationOptions) (*KeyRotation, *Response, error)
 	Update(string, string, string, Credential) (*Credential, *Response, error)
 }
 
@@ -119,3 +130,135 @@ func (s *CompanyAppCredentialsServiceOp) RemoveApiProduct(companyName string, ap
 	return resp, e
 
 }
+
+// Add API products to a company app's consumer key. Existing products are kept.
+func (s *CompanyAppCredentialsServiceOp) AddApiProducts(companyName string, appName string, consumerKey string, apiProductNames []string) (*Credential, *Response, error) {
+
+	uripath := path.Join(companiesPath, companyName, appPath, appName, keysPath, consumerKey)
+
+	// Unlike a Credential, the request lists the products by name only.
+	body := struct {
+		ApiProducts []string `json:"apiProducts"`
+	}{apiProductNames}
+	req, e := s.client.NewRequest("POST", uripath, body)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	returnedCompanyAppCredential := Credential{}
+
+	resp, e := s.client.Do(req, &returnedCompanyAppCredential)
+	if e != nil {
+		return nil, resp, e
+	}
+
+	return &returnedCompanyAppCredential, resp, e
+
+}
+
+// Generate a new consumer key and secret for a company app, with the given API
+// products, that expires after keyExpiresIn, or never when it is zero. Edge sets
+// the expiry of a key only when it generates one, from the keyExpiresIn
+// property, in milliseconds, of an update to the app, and it cannot be changed
+// afterwards. Edge replaces the whole app on update, so the app is read first
+// and sent back unchanged apart from the products and keyExpiresIn.
+func (s *CompanyAppCredentialsServiceOp) Generate(companyName string, appName string, apiProductNames []string, keyExpiresIn time.Duration) (*Credential, *Response, error) {
+
+	app, resp, e := s.client.CompanyApps.Get(companyName, appName)
+	if e != nil {
+		return nil, resp, e
+	}
+	before := app.Credentials
+
+	uripath := path.Join(companiesPath, companyName, appPath, appName)
+
+	body := struct {
+		CompanyApp
+		KeyExpiresIn string `json:"keyExpiresIn,omitempty"`
+	}{CompanyApp: *app}
+	body.ApiProducts = apiProductNames
+	body.Credentials = nil
+	if keyExpiresIn > 0 {
+		body.KeyExpiresIn = strconv.FormatInt(keyExpiresIn.Milliseconds(), 10)
+	}
+	req, e := s.client.NewRequest("PUT", uripath, body)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	returnedCompanyApp := CompanyApp{}
+
+	resp, e = s.client.Do(req, &returnedCompanyApp)
+	if e != nil {
+		return nil, resp, e
+	}
+
+	generated := generatedCredential(before, returnedCompanyApp.Credentials)
+	if generated == nil {
+		return nil, resp, fmt.Errorf("no new key in company app %s after update", appName)
+	}
+	return generated, resp, e
+
+}
+
+// generatedCredential returns the credential in after whose key is not in
+// before, or nil if there is none.
+func generatedCredential(before, after []Credential) *Credential {
+	existing := map[string]bool{}
+	for _, c := range before {
+		existing[c.ConsumerKey] = true
+	}
+	for i := range after {
+		if !existing[after[i].ConsumerKey] {
+			return &after[i]
+		}
+	}
+	return nil
+}
+
+func updateCredentialStatus(client *ApigeeClient, uripath string, desiredStatus string) (*Response, error) {
+
+	// append the necessary query param
+	origURL, e := url.Parse(uripath)
+	if e != nil {
+		return nil, e
+	}
+	q := origURL.Query()
+	q.Add("action", desiredStatus)
+	origURL.RawQuery = q.Encode()
+	uripath = origURL.String()
+
+	req, e := client.NewRequest("POST", uripath, nil)
+	if e != nil {
+		return nil, e
+	}
+	resp, e := client.Do(req, nil)
+	if e != nil {
+		return resp, e
+	}
+	return resp, e
+}
+
+// Approve a company app's consumer key
+func (s *CompanyAppCredentialsServiceOp) Approve(companyName string, appName string, consumerKey string) (*Response, error) {
+	uripath := path.Join(companiesPath, companyName, appPath, appName, keysPath, consumerKey)
+	return updateCredentialStatus(s.client, uripath, "approve")
+}
+
+// Revoke a company app's consumer key. Requests using it are rejected from then on.
+func (s *CompanyAppCredentialsServiceOp) Revoke(companyName string, appName string, consumerKey string) (*Response, error) {
+	uripath := path.Join(companiesPath, companyName, appPath, appName, keysPath, consumerKey)
+	return updateCredentialStatus(s.client, uripath, "revoke")
+}
+
+// Approve an API product on a company app's consumer key
+func (s *CompanyAppCredentialsServiceOp) ApproveApiProduct(companyName string, appName string, consumerKey string, apiProductName string) (*Response, error) {
+	uripath := path.Join(companiesPath, companyName, appPath, appName, keysPath, consumerKey, productsPath, apiProductName)
+	return updateCredentialStatus(s.client, uripath, "approve")
+}
+
+// Revoke an API product on a company app's consumer key, leaving the product listed on the key
+func (s *CompanyAppCredentialsServiceOp) RevokeApiProduct(companyName string, appName string, consumerKey string, apiProductName string) (*Response, error) {
+	uripath := path.Join(companiesPath, companyName, appPath, appName, keysPath, consumerKey, productsPath, apiProductName)
+	return updateCredentialStatus(s.client, uripath, "revoke")
+}
@@ -0,0 +1,99 @@
+package apigee
+
+import (
+	"fmt"
+	"path"
+)
+
+// CompanyDevelopersService is an interface for interfacing with the Apigee Edge Admin API
+// dealing with the developers that belong to a company, and their roles.
+type CompanyDevelopersService interface {
+	Add(string, string, string) ([]CompanyDeveloper, *Response, error)
+	List(string) ([]CompanyDeveloper, *Response, error)
+	Remove(string, string) (*CompanyDeveloper, *Response, error)
+	SetRole(string, string, string) ([]CompanyDeveloper, *Response, error)
+}
+
+type CompanyDevelopersServiceOp struct {
+	client *ApigeeClient
+}
+
+var _ CompanyDevelopersService = &CompanyDevelopersServiceOp{}
+
+// CompanyDeveloper is a developer that belongs to a company, with the role the
+// developer has in it. Roles are free-form, eg "admin" or "member".
+type CompanyDeveloper struct {
+	Email string `json:"email,omitempty"`
+	Role  string `json:"role,omitempty"`
+}
+
+// companyDeveloperList is the form in which Edge sends and receives the developers of a company.
+type companyDeveloperList struct {
+	Developers []CompanyDeveloper `json:"developer"`
+}
+
+// List retrieves the developers of a company.
+func (s *CompanyDevelopersServiceOp) List(companyName string) ([]CompanyDeveloper, *Response, error) {
+	path := path.Join(companiesPath, companyName, developersPath)
+	req, e := s.client.NewRequest("GET", path, nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	list := companyDeveloperList{}
+	resp, e := s.client.Do(req, &list)
+	if e != nil {
+		return nil, resp, e
+	}
+	if list.Developers == nil {
+		list.Developers = []CompanyDeveloper{}
+	}
+	return list.Developers, resp, e
+}
+
+// Add adds a developer, who must already exist in the organization, to a
+// company with the given role. If the developer already belongs to the
+// company, the role is changed.
+func (s *CompanyDevelopersServiceOp) Add(companyName, developerEmail, role string) ([]CompanyDeveloper, *Response, error) {
+	path := path.Join(companiesPath, companyName, developersPath)
+	list := companyDeveloperList{[]CompanyDeveloper{{Email: developerEmail, Role: role}}}
+	req, e := s.client.NewRequest("POST", path, list)
+	if e != nil {
+		return nil, nil, e
+	}
+	returnedList := companyDeveloperList{}
+	resp, e := s.client.Do(req, &returnedList)
+	if e != nil {
+		return nil, resp, e
+	}
+	return returnedList.Developers, resp, e
+}
+
+// SetRole changes the role of a developer that belongs to a company. Unlike Add,
+// it is an error if the developer does not belong to the company.
+func (s *CompanyDevelopersServiceOp) SetRole(companyName, developerEmail, role string) ([]CompanyDeveloper, *Response, error) {
+	developers, resp, e := s.List(companyName)
+	if e != nil {
+		return nil, resp, e
+	}
+	for _, d := range developers {
+		if d.Email == developerEmail {
+			return s.Add(companyName, developerEmail, role)
+		}
+	}
+	return nil, resp, fmt.Errorf("developer %s does not belong to company %s", developerEmail, companyName)
+}
+
+// Remove removes a developer from a company. The developer is not deleted.
+func (s *CompanyDevelopersServiceOp) Remove(companyName, developerEmail string) (*CompanyDeveloper, *Response, error) {
+	path := path.Join(companiesPath, companyName, developersPath, developerEmail)
+	req, e := s.client.NewRequest("DELETE", path, nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	removedDeveloper := CompanyDeveloper{}
+	resp, e := s.client.Do(req, &removedDeveloper)
+	if e != nil {
+		return nil, resp, e
+	}
+	return &removedDeveloper, resp, e
+}
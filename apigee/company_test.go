@@ -0,0 +1,70 @@
+package apigee
+
+import (
+	"testing"
+)
+
+func TestCompanyDevelopers(t *testing.T) {
+	client := NewClientForTesting(t)
+	dev, e := randomDeveloperFromTemplate()
+	createdDeveloper, _, e := client.Developers.Create(dev)
+	if e != nil {
+		t.Errorf("while creating Edge developer, error:\n%#v\n", e)
+		return
+	}
+	defer client.Developers.Delete(createdDeveloper.Email)
+
+	name := testPrefix + randomString(7)
+	createdCompany, _, e := client.Companies.Create(Company{Name: name, DisplayName: name})
+	if e != nil {
+		t.Errorf("while creating company, error:\n%#v\n", e)
+		return
+	}
+	defer client.Companies.Delete(createdCompany.Name)
+
+	names, _, e := client.Companies.List()
+	if e != nil {
+		t.Errorf("while listing companies, error:\n%#v\n", e)
+		return
+	}
+	found := false
+	for _, n := range names {
+		found = found || n == name
+	}
+	if !found {
+		t.Errorf("List: %s not found in %v", name, names)
+	}
+
+	if _, _, e := client.CompanyDevelopers.SetRole(name, createdDeveloper.Email, "admin"); e == nil {
+		t.Errorf("SetRole for a developer not in the company, expected an error")
+	}
+	if _, _, e := client.CompanyDevelopers.Add(name, createdDeveloper.Email, "member"); e != nil {
+		t.Errorf("while adding developer, error:\n%#v\n", e)
+		return
+	}
+	if _, _, e := client.CompanyDevelopers.SetRole(name, createdDeveloper.Email, "admin"); e != nil {
+		t.Errorf("while setting role, error:\n%#v\n", e)
+	}
+	developers, _, e := client.CompanyDevelopers.List(name)
+	if e != nil {
+		t.Errorf("while listing company developers, error:\n%#v\n", e)
+		return
+	}
+	if len(developers) != 1 || developers[0].Role != "admin" {
+		t.Errorf("List developers: got=%v", developers)
+	}
+	if _, _, e := client.CompanyDevelopers.Remove(name, createdDeveloper.Email); e != nil {
+		t.Errorf("while removing developer, error:\n%#v\n", e)
+	}
+
+	if _, e := client.Companies.Revoke(name); e != nil {
+		t.Errorf("while revoking company, error:\n%#v\n", e)
+	}
+	if _, e := client.Companies.Approve(name); e != nil {
+		t.Errorf("while approving company, error:\n%#v\n", e)
+	}
+	apps, _, e := client.CompanyApps.List(name)
+	if e != nil || len(apps) != 0 {
+		t.Errorf("CompanyApps.List: got=%v, error=%v", apps, e)
+	}
+}
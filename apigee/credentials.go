@@ -0,0 +1,239 @@
+package apigee
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MarshalJSON implements the json.Marshaler interface. ExpiresAt and IssuedAt
+// are omitted when zero, as they were when they were plain numbers.
+func (c Credential) MarshalJSON() ([]byte, error) {
+	type credential Credential
+	aux := struct {
+		credential
+		ExpiresAt *Timestamp `json:"expiresAt,omitempty"`
+		IssuedAt  *Timestamp `json:"issuedAt,omitempty"`
+	}{credential: credential(c)}
+	if !c.ExpiresAt.IsZero() {
+		aux.ExpiresAt = &c.ExpiresAt
+	}
+	if !c.IssuedAt.IsZero() {
+		aux.IssuedAt = &c.IssuedAt
+	}
+	return json.Marshal(aux)
+}
+
+// Expires reports whether the credential has an expiry. Edge reports a
+// credential that never expires with an ExpiresAt of -1.
+func (c Credential) Expires() bool {
+	return c.ExpiresAt.After(time.Unix(0, 0))
+}
+
+// ExpiresBefore reports whether the credential expires before t.
+func (c Credential) ExpiresBefore(t time.Time) bool {
+	return c.Expires() && c.ExpiresAt.Before(t)
+}
+
+// PendingApiProducts returns the API products awaiting approval on the credential.
+func (c Credential) PendingApiProducts() []string {
+	pending := []string{}
+	for _, p := range c.ApiProducts {
+		if p.Status == "pending" {
+			pending = append(pending, p.ApiProduct)
+		}
+	}
+	return pending
+}
+
+// CredentialIssue is a kind of problem reported by a CredentialReport.
+type CredentialIssue string
+
+const (
+	// An approved key of an approved app has expired.
+	CredentialExpired CredentialIssue = "expired"
+	// An approved key of an approved app expires within the report window.
+	CredentialExpiring CredentialIssue = "expiring"
+	// A revoked key remains attached to an approved app.
+	CredentialRevokedOnActiveApp CredentialIssue = "revoked-on-active-app"
+	// A key has API products awaiting approval.
+	CredentialPendingApproval CredentialIssue = "pending-approval"
+)
+
+// CredentialReportEntry is one problem with one credential. Owner is the email
+// of the developer or the name of the company that owns the app. Products
+// lists the pending products for CredentialPendingApproval, and every product
+// on the key otherwise.
+type CredentialReportEntry struct {
+	Issue       CredentialIssue `json:"issue"`
+	OwnerType   string          `json:"ownerType"`
+	Owner       string          `json:"owner"`
+	AppName     string          `json:"appName"`
+	AppId       string          `json:"appId"`
+	AppStatus   string          `json:"appStatus"`
+	ConsumerKey string          `json:"consumerKey"`
+	KeyStatus   string          `json:"keyStatus"`
+	ExpiresAt   *time.Time      `json:"expiresAt,omitempty"`
+	Products    []string        `json:"products"`
+}
+
+// CredentialReport lists the credentials of developer and company apps that
+// need attention: those expired or expiring within Window of GeneratedAt,
+// revoked keys still attached to approved apps, and keys with API products
+// awaiting approval. In JSON, Window is a duration string such as "720h0m0s".
+type CredentialReport struct {
+	GeneratedAt time.Time               `json:"generatedAt"`
+	Window      time.Duration           `json:"-"`
+	Entries     []CredentialReportEntry `json:"entries"`
+}
+
+type credentialReport CredentialReport
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r CredentialReport) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		credentialReport
+		Window string `json:"window"`
+	}{credentialReport(r), r.Window.String()})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *CredentialReport) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		*credentialReport
+		Window string `json:"window"`
+	}{credentialReport: (*credentialReport)(r)}
+	if e := json.Unmarshal(data, &aux); e != nil {
+		return e
+	}
+	if aux.Window == "" {
+		r.Window = 0
+		return nil
+	}
+	window, e := time.ParseDuration(aux.Window)
+	if e != nil {
+		return fmt.Errorf("invalid window %q: %v", aux.Window, e)
+	}
+	r.Window = window
+	return nil
+}
+
+// GetCredentialReport examines every app in the organization and reports the
+// credentials that expire within window, or that are revoked or pending.
+func (s *AppsServiceOp) GetCredentialReport(window time.Duration) (*CredentialReport, error) {
+	apps, _, e := s.ListAll(&AppsListOptions{IncludeCredentials: true})
+	if e != nil {
+		return nil, fmt.Errorf("while listing apps, error: %v", e)
+	}
+	developers, _, e := s.client.Developers.ListExpanded()
+	if e != nil {
+		return nil, fmt.Errorf("while listing developers, error: %v", e)
+	}
+	emails := map[string]string{}
+	for _, d := range developers {
+		emails[d.Id] = d.Email
+	}
+	now := time.Now()
+	return &CredentialReport{
+		GeneratedAt: now,
+		Window:      window,
+		Entries:     credentialReportEntries(apps, emails, now, window),
+	}, nil
+}
+
+// credentialReportEntries finds the credentials that need attention. emails
+// maps developer IDs to emails.
+func credentialReportEntries(apps []App, emails map[string]string, now time.Time, window time.Duration) []CredentialReportEntry {
+	entries := []CredentialReportEntry{}
+	for _, app := range apps {
+		ownerType, owner := "developer", emails[app.DeveloperId]
+		if app.IsCompanyApp() {
+			ownerType, owner = "company", app.CompanyName
+		} else if owner == "" {
+			owner = app.DeveloperId
+		}
+		for _, c := range app.Credentials {
+			entry := CredentialReportEntry{
+				OwnerType:   ownerType,
+				Owner:       owner,
+				AppName:     app.Name,
+				AppId:       app.AppId,
+				AppStatus:   app.Status,
+				ConsumerKey: c.ConsumerKey,
+				KeyStatus:   c.Status,
+				Products:    []string{},
+			}
+			if c.Expires() {
+				expiresAt := c.ExpiresAt.Time
+				entry.ExpiresAt = &expiresAt
+			}
+			for _, p := range c.ApiProducts {
+				entry.Products = append(entry.Products, p.ApiProduct)
+			}
+			add := func(issue CredentialIssue) {
+				e := entry
+				e.Issue = issue
+				entries = append(entries, e)
+			}
+			appActive := app.Status == AppStatusApproved
+			switch {
+			case appActive && c.Status == "approved" && c.ExpiresBefore(now):
+				add(CredentialExpired)
+			case appActive && c.Status == "approved" && c.ExpiresBefore(now.Add(window)):
+				add(CredentialExpiring)
+			case appActive && c.Status == "revoked":
+				add(CredentialRevokedOnActiveApp)
+			}
+			if pending := c.PendingApiProducts(); len(pending) > 0 {
+				entry.Products = pending
+				add(CredentialPendingApproval)
+			}
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Issue != entries[j].Issue {
+			return entries[i].Issue < entries[j].Issue
+		}
+		if entries[i].Owner != entries[j].Owner {
+			return entries[i].Owner < entries[j].Owner
+		}
+		return entries[i].AppName < entries[j].AppName
+	})
+	return entries
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *CredentialReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes the entries of the report as CSV with a header row. Times
+// are in RFC 3339 format and products are separated by spaces.
+func (r *CredentialReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"issue", "ownerType", "owner", "appName", "appId", "appStatus", "consumerKey", "keyStatus", "expiresAt", "products"}
+	if e := cw.Write(header); e != nil {
+		return e
+	}
+	for _, entry := range r.Entries {
+		expiresAt := ""
+		if entry.ExpiresAt != nil {
+			expiresAt = entry.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+		record := []string{
+			string(entry.Issue), entry.OwnerType, entry.Owner, entry.AppName, entry.AppId,
+			entry.AppStatus, entry.ConsumerKey, entry.KeyStatus, expiresAt, strings.Join(entry.Products, " "),
+		}
+		if e := cw.Write(record); e != nil {
+			return e
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,96 @@
+package apigee
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCredentialTimestamps(t *testing.T) {
+	c := Credential{}
+	if e := json.Unmarshal([]byte(`{"consumerKey": "k1", "expiresAt": -1, "issuedAt": 1600000000000}`), &c); e != nil {
+		t.Fatalf("while unmarshaling, error: %v", e)
+	}
+	if c.Expires() {
+		t.Errorf("expiresAt -1: expected no expiry")
+	}
+	if !c.IssuedAt.Equal(Timestamp{time.Unix(1600000000, 0)}) {
+		t.Errorf("issuedAt: got=%v", c.IssuedAt.Time)
+	}
+
+	out, e := json.Marshal(Credential{ConsumerKey: "k1"})
+	if e != nil {
+		t.Fatalf("while marshaling, error: %v", e)
+	}
+	if got := string(out); got != `{"consumerKey":"k1"}` {
+		t.Errorf("zero timestamps: got=%s", got)
+	}
+	out, e = json.Marshal(Credential{ConsumerKey: "k1", ExpiresAt: Timestamp{time.Unix(1700000000, 0)}})
+	if e != nil {
+		t.Fatalf("while marshaling, error: %v", e)
+	}
+	if got := string(out); got != `{"consumerKey":"k1","expiresAt":1700000000000}` {
+		t.Errorf("expiresAt: got=%s", got)
+	}
+}
+
+func TestCredentialReportEntries(t *testing.T) {
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) Timestamp { return Timestamp{now.Add(d)} }
+	never := Timestamp{time.Unix(0, -int64(time.Millisecond))}
+	apps := []App{
+		{Name: "one", AppId: "id-1", DeveloperId: "dev-1", Status: "approved", Credentials: []Credential{
+			{ConsumerKey: "expired", Status: "approved", ExpiresAt: at(-time.Hour)},
+			{ConsumerKey: "soon", Status: "approved", ExpiresAt: at(48 * time.Hour)},
+			{ConsumerKey: "later", Status: "approved", ExpiresAt: at(90 * 24 * time.Hour)},
+			{ConsumerKey: "forever", Status: "approved", ExpiresAt: never},
+			{ConsumerKey: "revoked", Status: "revoked", ExpiresAt: at(time.Hour)},
+		}},
+		{Name: "two", AppId: "id-2", CompanyName: "acme", Status: "approved", Credentials: []Credential{
+			{ConsumerKey: "pending", Status: "approved", ApiProducts: []CredentialApiProduct{{"gold", "pending"}, {"silver", "approved"}}},
+		}},
+		{Name: "three", AppId: "id-3", DeveloperId: "dev-2", Status: "revoked", Credentials: []Credential{
+			{ConsumerKey: "ignored", Status: "revoked", ExpiresAt: at(time.Hour)},
+		}},
+	}
+	entries := credentialReportEntries(apps, map[string]string{"dev-1": "a@example.com"}, now, 7*24*time.Hour)
+	got := []string{}
+	for _, e := range entries {
+		got = append(got, string(e.Issue)+":"+e.Owner+":"+e.ConsumerKey)
+	}
+	expected := []string{
+		"expired:a@example.com:expired",
+		"expiring:a@example.com:soon",
+		"pending-approval:acme:pending",
+		"revoked-on-active-app:a@example.com:revoked",
+	}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("got=%v, expected=%v", got, expected)
+	}
+	if len(entries) == 4 && (len(entries[2].Products) != 1 || entries[2].Products[0] != "gold") {
+		t.Errorf("pending products: got=%v", entries[2].Products)
+	}
+
+	report := &CredentialReport{GeneratedAt: now, Window: time.Hour, Entries: entries[:2]}
+	buf := new(bytes.Buffer)
+	if e := report.WriteCSV(buf); e != nil {
+		t.Fatalf("while writing CSV, error: %v", e)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || lines[1] != "expired,developer,a@example.com,one,id-1,approved,expired,approved,2020-05-31T23:00:00Z," {
+		t.Errorf("CSV: got=%q", lines)
+	}
+	buf.Reset()
+	if e := report.WriteJSON(buf); e != nil {
+		t.Fatalf("while writing JSON, error: %v", e)
+	}
+	if !strings.Contains(buf.String(), `"window": "1h0m0s"`) {
+		t.Errorf("JSON window: got=%s", buf.String())
+	}
+	decoded := CredentialReport{}
+	if e := json.Unmarshal(buf.Bytes(), &decoded); e != nil || len(decoded.Entries) != 2 || decoded.Window != time.Hour {
+		t.Errorf("JSON: got=%s, error=%v", buf.String(), e)
+	}
+}
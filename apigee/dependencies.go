@@ -0,0 +1,239 @@
+package apigee
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/brayanhenao/go-apigee-edge/bundle"
+)
+
+// DependencyNodeKind distinguishes the kinds of node in a DependencyGraph.
+type DependencyNodeKind string
+
+const (
+	ProxyNode        DependencyNodeKind = "proxy"
+	SharedFlowNode   DependencyNodeKind = "sharedflow"
+	FlowHookNode     DependencyNodeKind = "flowhook"
+	TargetServerNode DependencyNodeKind = "targetserver"
+)
+
+// DependencyNode is an API Proxy, SharedFlow, flow hook or target server within
+// an environment. Revision is the deployed revision of a proxy or shared flow.
+// It is 0 for a proxy or shared flow that is referenced but not deployed, and
+// for flow hooks and target servers.
+type DependencyNode struct {
+	Kind        DependencyNodeKind
+	Name        string
+	Environment string
+	Revision    Revision
+}
+
+func (n DependencyNode) String() string {
+	if n.Revision > 0 {
+		return fmt.Sprintf("%s %s revision %d in %s", n.Kind, n.Name, n.Revision, n.Environment)
+	}
+	return fmt.Sprintf("%s %s in %s", n.Kind, n.Name, n.Environment)
+}
+
+// DependencyEdge records that From depends on To.
+type DependencyEdge struct {
+	From DependencyNode
+	To   DependencyNode
+}
+
+// DependencyGraph relates the deployed proxies and shared flows in one or more
+// environments to the shared flows they call, the target servers they use, and
+// the flow hooks that attach shared flows.
+type DependencyGraph struct {
+	Nodes []DependencyNode
+	Edges []DependencyEdge
+}
+
+func (g *DependencyGraph) addNode(n DependencyNode) {
+	for _, existing := range g.Nodes {
+		if existing == n {
+			return
+		}
+	}
+	g.Nodes = append(g.Nodes, n)
+}
+
+func (g *DependencyGraph) addEdge(from, to DependencyNode) {
+	g.addNode(from)
+	g.addNode(to)
+	g.Edges = append(g.Edges, DependencyEdge{From: from, To: to})
+}
+
+// DependsOn returns the nodes that n depends on directly.
+func (g *DependencyGraph) DependsOn(n DependencyNode) []DependencyNode {
+	nodes := []DependencyNode{}
+	for _, edge := range g.Edges {
+		if edge.From == n {
+			nodes = append(nodes, edge.To)
+		}
+	}
+	return nodes
+}
+
+// Dependents returns the nodes that depend, directly or through other nodes, on
+// any node of the given kind and name. When env is not empty, only nodes in that
+// environment are considered.
+func (g *DependencyGraph) Dependents(kind DependencyNodeKind, name, env string) []DependencyNode {
+	visited := map[DependencyNode]bool{}
+	queue := []DependencyNode{}
+	for _, n := range g.Nodes {
+		if n.Kind == kind && n.Name == name && (env == "" || n.Environment == env) {
+			visited[n] = true
+			queue = append(queue, n)
+		}
+	}
+	dependents := []DependencyNode{}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, edge := range g.Edges {
+			if edge.To != n || visited[edge.From] {
+				continue
+			}
+			visited[edge.From] = true
+			dependents = append(dependents, edge.From)
+			queue = append(queue, edge.From)
+		}
+	}
+	return dependents
+}
+
+// addBundle adds the edges from a deployed proxy or shared flow to the entities
+// its bundle refers to. deployedSharedFlows maps the name of each shared flow
+// deployed in the environment to its revision.
+func (g *DependencyGraph) addBundle(from DependencyNode, refs bundle.References, deployedSharedFlows map[string]Revision) {
+	g.addNode(from)
+	for _, name := range refs.SharedFlows {
+		g.addEdge(from, DependencyNode{Kind: SharedFlowNode, Name: name, Environment: from.Environment, Revision: deployedSharedFlows[name]})
+	}
+	for _, name := range refs.TargetServers {
+		g.addEdge(from, DependencyNode{Kind: TargetServerNode, Name: name, Environment: from.Environment})
+	}
+	for _, name := range refs.Proxies {
+		g.addEdge(from, DependencyNode{Kind: ProxyNode, Name: name, Environment: from.Environment})
+	}
+}
+
+// GetDependencyGraph builds the dependency graph for the named environments,
+// or for every environment in the organization when none are named. Each
+// deployed revision is exported to find the shared flows, target servers and
+// proxies it refers to.
+func (s *EnvironmentsServiceOp) GetDependencyGraph(envs ...string) (*DependencyGraph, error) {
+	client := s.client
+	if len(envs) == 0 {
+		all, _, e := client.Environments.List()
+		if e != nil {
+			return nil, e
+		}
+		envs = all
+	}
+	sort.Strings(envs)
+
+	type deployed struct {
+		node           DependencyNode
+		uriPathElement string
+	}
+	assets := []deployed{}
+	sharedFlowsByEnv := map[string]map[string]Revision{}
+	g := &DependencyGraph{}
+	for _, env := range envs {
+		proxies, _, e := client.Environments.GetDeployments(env)
+		if e != nil {
+			return nil, e
+		}
+		for _, proxy := range proxies.Proxies {
+			for _, rd := range proxy.Revisions {
+				node := DependencyNode{Kind: ProxyNode, Name: proxy.Name, Environment: env, Revision: rd.Number}
+				assets = append(assets, deployed{node, proxiesPath})
+			}
+		}
+		sharedFlows, _, e := client.Environments.GetSharedFlowDeployments(env)
+		if e != nil {
+			return nil, e
+		}
+		sharedFlowsByEnv[env] = map[string]Revision{}
+		for _, sf := range sharedFlows.SharedFlows {
+			for _, rd := range sf.Revisions {
+				node := DependencyNode{Kind: SharedFlowNode, Name: sf.Name, Environment: env, Revision: rd.Number}
+				assets = append(assets, deployed{node, sharedFlowPath})
+				sharedFlowsByEnv[env][sf.Name] = rd.Number
+			}
+		}
+
+		hooks, _, e := client.FlowHooks.List(env)
+		if e != nil {
+			return nil, e
+		}
+		for _, hook := range hooks {
+			flowHook, _, e := client.FlowHooks.Get(env, hook)
+			if e != nil {
+				return nil, e
+			}
+			if flowHook.SharedFlow == "" {
+				continue
+			}
+			from := DependencyNode{Kind: FlowHookNode, Name: hook, Environment: env}
+			to := DependencyNode{Kind: SharedFlowNode, Name: flowHook.SharedFlow, Environment: env, Revision: sharedFlowsByEnv[env][flowHook.SharedFlow]}
+			g.addEdge(from, to)
+		}
+	}
+
+	// The same revision is often deployed to several environments, so export
+	// each revision only once.
+	var mu sync.Mutex
+	references := map[string]bundle.References{}
+	key := func(a deployed) string {
+		return fmt.Sprintf("%s/%s/%d", a.uriPathElement, a.node.Name, a.node.Revision)
+	}
+	unique := []deployed{}
+	seen := map[string]bool{}
+	for _, a := range assets {
+		if !seen[key(a)] {
+			seen[key(a)] = true
+			unique = append(unique, a)
+		}
+	}
+	failed := runParallel(len(unique), defaultParallelism, func(i int) error {
+		a := unique[i]
+		b, _, e := (&Deployable{}).ExportBundle(client, a.uriPathElement, a.node.Name, a.node.Revision)
+		if e != nil {
+			return fmt.Errorf("while exporting %s, error: %v", a.node, e)
+		}
+		mu.Lock()
+		references[key(a)] = b.References()
+		mu.Unlock()
+		return nil
+	})
+	for i := range unique {
+		if e, ok := failed[i]; ok {
+			return nil, e
+		}
+	}
+
+	for _, a := range assets {
+		g.addBundle(a.node, references[key(a)], sharedFlowsByEnv[a.node.Environment])
+	}
+	return g, nil
+}
+
+// DependentsError is returned when an operation on a shared flow is refused
+// because deployed proxies, shared flows or flow hooks depend on it.
+type DependentsError struct {
+	SharedFlow string
+	Dependents []DependencyNode
+}
+
+func (e *DependentsError) Error() string {
+	names := []string{}
+	for _, n := range e.Dependents {
+		names = append(names, n.String())
+	}
+	return fmt.Sprintf("shared flow %s is in use by %s", e.SharedFlow, strings.Join(names, ", "))
+}
@@ -0,0 +1,63 @@
+package apigee
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brayanhenao/go-apigee-edge/bundle"
+)
+
+func TestDependents(t *testing.T) {
+	deployedSharedFlows := map[string]Revision{"auth": 3, "logging": 1}
+	orders := DependencyNode{Kind: ProxyNode, Name: "orders", Environment: "prod", Revision: 7}
+	auth := DependencyNode{Kind: SharedFlowNode, Name: "auth", Environment: "prod", Revision: 3}
+	logging := DependencyNode{Kind: SharedFlowNode, Name: "logging", Environment: "prod", Revision: 1}
+	hook := DependencyNode{Kind: FlowHookNode, Name: "PreProxyFlowHook", Environment: "prod"}
+	testOrders := DependencyNode{Kind: ProxyNode, Name: "orders", Environment: "test", Revision: 8}
+
+	g := &DependencyGraph{}
+	g.addBundle(orders, bundle.References{SharedFlows: []string{"auth"}, TargetServers: []string{"orders-backend"}}, deployedSharedFlows)
+	g.addBundle(auth, bundle.References{SharedFlows: []string{"logging"}}, deployedSharedFlows)
+	g.addBundle(logging, bundle.References{}, deployedSharedFlows)
+	g.addBundle(testOrders, bundle.References{SharedFlows: []string{"logging"}}, map[string]Revision{})
+	g.addEdge(hook, logging)
+
+	testCases := []struct {
+		desc     string
+		kind     DependencyNodeKind
+		name     string
+		env      string
+		expected []DependencyNode
+	}{
+		{"transitive", SharedFlowNode, "logging", "prod", []DependencyNode{auth, hook, orders}},
+		{"all environments", SharedFlowNode, "logging", "", []DependencyNode{auth, hook, testOrders, orders}},
+		{"target server", TargetServerNode, "orders-backend", "prod", []DependencyNode{orders}},
+		{"unused", ProxyNode, "orders", "prod", []DependencyNode{}},
+	}
+	for _, tc := range testCases {
+		actual := g.Dependents(tc.kind, tc.name, tc.env)
+		if len(actual) != len(tc.expected) {
+			t.Errorf("%s: got=%v, expected=%v", tc.desc, actual, tc.expected)
+			continue
+		}
+		for i := range actual {
+			if actual[i] != tc.expected[i] {
+				t.Errorf("%s: got=%v, expected=%v", tc.desc, actual, tc.expected)
+				break
+			}
+		}
+	}
+
+	// A shared flow that is referenced but not deployed has revision 0.
+	missing := g.DependsOn(testOrders)
+	if len(missing) != 1 || missing[0].Revision != 0 || missing[0].Environment != "test" {
+		t.Errorf("depends on: got=%v", missing)
+	}
+
+	var e error = &DependentsError{SharedFlow: "logging", Dependents: []DependencyNode{hook, orders}}
+	var de *DependentsError
+	if !errors.As(e, &de) || !strings.Contains(e.Error(), "flowhook PreProxyFlowHook in prod, proxy orders revision 7 in prod") {
+		t.Errorf("error: got=%s", e)
+	}
+}
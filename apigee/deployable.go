@@ -1,11 +1,11 @@
 package apigee
 
 import (
-	"archive/zip"
+	"bytes"
 	"errors"
 	"fmt"
-	"io"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"path"
@@ -13,6 +13,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/brayanhenao/go-apigee-edge/bundle"
 )
 
 // DeployableAsset contains information about an API Proxy or SharedFlow within an Apigee organization.
@@ -85,9 +87,16 @@ type EnvironmentDeployment struct {
 }
 
 type RevisionDeployment struct {
-	Number  Revision       `json:"name,omitempty"`
-	Servers []ApigeeServer `json:"server,omitempty"`
-	State   string         `json:"state,omitempty"`
+	Configuration *DeploymentConfiguration `json:"configuration,omitempty"`
+	Number        Revision                 `json:"name,omitempty"`
+	Servers       []ApigeeServer           `json:"server,omitempty"`
+	State         string                   `json:"state,omitempty"`
+}
+
+// DeploymentConfiguration holds the configuration of a deployed revision, when
+// the Admin API reports it.
+type DeploymentConfiguration struct {
+	BasePath string `json:"basePath,omitempty"`
 }
 
 type Deployable struct{}
@@ -119,127 +128,109 @@ func (s *Deployable) Get(client *ApigeeClient, uriPathElement, assetName string)
 	return &returnedAsset, resp, e
 }
 
-func smartFilter(path string) bool {
-	if strings.HasSuffix(path, "~") {
-		return false
-	}
-	if strings.HasSuffix(path, "#") && strings.HasPrefix(path, "#") {
-		return false
+// bundleZip returns the name of a zip file holding the bundle at source, with
+// any template in opts applied. When source is a directory, or there is a
+// template, the bundle is zipped into a temporary file, which the returned
+// cleanup function removes.
+func bundleZip(uriPathElement, source string, opts *ImportOptions) (string, func(), error) {
+	zipfileName, cleanup, e := zipSource(uriPathElement, source, opts)
+	if e != nil || opts == nil || opts.Template == nil {
+		return zipfileName, cleanup, e
+	}
+	templatedName, templatedCleanup, e := templateZip(zipfileName, opts.Template)
+	cleanup()
+	if e != nil {
+		return "", nil, e
 	}
-	return true
+	return templatedName, templatedCleanup, nil
 }
 
-func zipDirectory(source string, target string, filter func(string) bool) error {
-	zipfile, err := os.Create(target)
-	if err != nil {
-		return err
-	}
-	defer zipfile.Close()
-
-	archive := zip.NewWriter(zipfile)
-	defer archive.Close()
-
+// zipSource returns the name of a zip file holding the bundle at source. When
+// source is a directory, the bundle is zipped into a temporary file, which the
+// returned cleanup function removes.
+func zipSource(uriPathElement, source string, opts *ImportOptions) (string, func(), error) {
 	info, err := os.Stat(source)
 	if err != nil {
-		return nil
+		return "", nil, err
 	}
-
-	var baseDir string
-	if info.IsDir() {
-		baseDir = filepath.Base(source)
+	if !info.IsDir() {
+		if !strings.HasSuffix(source, ".zip") {
+			return "", nil, errors.New("source must be a zipfile")
+		}
+		return source, func() {}, nil
 	}
 
-	filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
-		if filter == nil || filter(path) {
-			if err != nil {
-				return err
-			}
-
-			header, err := zip.FileInfoHeader(info)
-			if err != nil {
-				return err
-			}
-
-			if baseDir != "" {
-				header.Name = filepath.Join(baseDir, strings.TrimPrefix(path, source))
-			}
-
-			// This archive will be unzipped by a Java process.  When ZIP64 extensions
-			// are used, Java insists on having Deflate as the compression method (0x08)
-			// even for directories.
-			header.Method = zip.Deflate
-
-			if info.IsDir() {
-				header.Name += "/"
-			}
-
-			writer, err := archive.CreateHeader(header)
-			if err != nil {
-				return err
-			}
-
-			if info.IsDir() {
-				return nil
-			}
-
-			file, err := os.Open(path)
-			if err != nil {
-				return err
-			}
-			defer file.Close()
-			_, err = io.Copy(writer, file)
-		}
-		return err
-	})
+	// create a temporary zip file
+	tempDir, e := ioutil.TempDir("", "go-apigee-")
+	if e != nil {
+		return "", nil, errors.New(fmt.Sprintf("while creating temp dir, error: %#v", e))
+	}
+	cleanup := func() {
+		_ = os.RemoveAll(tempDir)
+	}
+	zipfileName := path.Join(tempDir, "bundle.zip")
+	var filePathElement string
+	if uriPathElement == "apis" {
+		filePathElement = "apiproxy"
+	} else {
+		filePathElement = "sharedflowbundle"
+	}
 
-	return err
+	filter, e := bundleFilter(source, opts)
+	if e == nil {
+		e = zipDirectory(path.Join(source, filePathElement), zipfileName, filter)
+	}
+	if e != nil {
+		cleanup()
+		return "", nil, errors.New(fmt.Sprintf("while zipping %s, error: %#v", source, e))
+	}
+	fmt.Printf("zipped %s into %s\n\n", source, zipfileName)
+	return zipfileName, cleanup, nil
 }
 
-func (s *Deployable) Import(client *ApigeeClient, uriPathElement, assetName, source string) (*DeployableRevision, *Response, error) {
+func (s *Deployable) Import(client *ApigeeClient, uriPathElement, assetName, source string, opts *ImportOptions) (*DeployableRevision, *Response, error) {
 	info, err := os.Stat(source)
 	if err != nil {
 		return nil, nil, err
 	}
-	zipfileName := source
-	if info.IsDir() {
-		// create a temporary zip file
-		if assetName == "" {
-			assetName = filepath.Base(source)
-		}
-		tempDir, e := ioutil.TempDir("", "go-apigee-")
-		if e != nil {
-			return nil, nil, errors.New(fmt.Sprintf("while creating temp dir, error: %#v", e))
-		}
-		zipfileName = path.Join(tempDir, "bundle.zip")
-		var filePathElement string
-		if uriPathElement == "apis" {
-			filePathElement = "apiproxy"
-		} else {
-			filePathElement = "sharedflowbundle"
-		}
-
-		e = zipDirectory(path.Join(source, filePathElement), zipfileName, smartFilter)
-		if e != nil {
-			return nil, nil, errors.New(fmt.Sprintf("while creating temp dir, error: %#v", e))
-		}
-		fmt.Printf("zipped %s into %s\n\n", source, zipfileName)
-		cleanup := func(filename string) {
-			_ = os.Remove(filename)
-			// if e != nil {
-			// 	//..
-			// }
-		}
-		defer cleanup(zipfileName)
-	}
-
-	if !strings.HasSuffix(zipfileName, ".zip") {
-		return nil, nil, errors.New("source must be a zipfile")
+	if info.IsDir() && assetName == "" {
+		assetName = filepath.Base(source)
 	}
-
-	info, err = os.Stat(zipfileName)
+	zipfileName, cleanup, err := bundleZip(uriPathElement, source, opts)
 	if err != nil {
 		return nil, nil, err
 	}
+	defer cleanup()
+
+	if opts != nil && (opts.SkipUnchanged || opts.RecordFingerprint) {
+		fingerprint, bundleName, err := zipFingerprint(zipfileName)
+		if err != nil {
+			return nil, nil, err
+		}
+		if opts.SkipUnchanged {
+			// A zip may be imported without naming the asset; compare with
+			// the revisions of the asset named in its descriptor.
+			name := assetName
+			if name == "" {
+				name = bundleName
+			}
+			if name == "" {
+				return nil, nil, fmt.Errorf("SkipUnchanged needs a name, and %s has none in its descriptor", source)
+			}
+			existing, resp, err := s.findRevisionByFingerprint(client, uriPathElement, name, fingerprint, opts.RecentRevisions)
+			if err != nil || existing != nil {
+				return existing, resp, err
+			}
+		}
+		if opts.RecordFingerprint {
+			stampedName, stampedCleanup, err := recordFingerprint(zipfileName, fingerprint)
+			if err != nil {
+				return nil, nil, err
+			}
+			defer stampedCleanup()
+			zipfileName = stampedName
+		}
+	}
 
 	// append the query params
 	origURL, err := url.Parse(uriPathElement)
@@ -250,8 +241,11 @@ func (s *Deployable) Import(client *ApigeeClient, uriPathElement, assetName, sou
 	q.Add("action", "import")
 	q.Add("name", assetName)
 	origURL.RawQuery = q.Encode()
-	path := origURL.String()
+	return uploadBundle(client, origURL.String(), zipfileName)
+}
 
+// uploadBundle POSTs a zipped bundle to the given path.
+func uploadBundle(client *ApigeeClient, path, zipfileName string) (*DeployableRevision, *Response, error) {
 	ioreader, err := os.Open(zipfileName)
 	if err != nil {
 		return nil, nil, err
@@ -270,7 +264,44 @@ func (s *Deployable) Import(client *ApigeeClient, uriPathElement, assetName, sou
 	return &returnedRevision, resp, e
 }
 
-func (s *Deployable) Export(client *ApigeeClient, uriPathElement, assetName string, rev Revision) (string, *Response, error) {
+// UpdateRevision replaces the contents of an existing revision of an API Proxy
+// or SharedFlow with the bundle at source, which can be a directory or a zip
+// file, as for Import. The options apply as for Import, except that
+// SkipUnchanged compares the bundle only with the revision being updated, and
+// RecentRevisions is not used.
+func (s *Deployable) UpdateRevision(client *ApigeeClient, uriPathElement, assetName string, rev Revision, source string, opts *ImportOptions) (*DeployableRevision, *Response, error) {
+	zipfileName, cleanup, err := bundleZip(uriPathElement, source, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cleanup()
+
+	if opts != nil && (opts.SkipUnchanged || opts.RecordFingerprint) {
+		fingerprint, _, err := zipFingerprint(zipfileName)
+		if err != nil {
+			return nil, nil, err
+		}
+		if opts.SkipUnchanged {
+			existing, resp, err := s.revisionWithFingerprint(client, uriPathElement, assetName, rev, fingerprint)
+			if err != nil || existing != nil {
+				return existing, resp, err
+			}
+		}
+		if opts.RecordFingerprint {
+			stampedName, stampedCleanup, err := recordFingerprint(zipfileName, fingerprint)
+			if err != nil {
+				return nil, nil, err
+			}
+			defer stampedCleanup()
+			zipfileName = stampedName
+		}
+	}
+
+	path := path.Join(uriPathElement, assetName, "revisions", fmt.Sprintf("%d", rev))
+	return uploadBundle(client, path, zipfileName)
+}
+
+func newExportRequest(client *ApigeeClient, uriPathElement, assetName string, rev Revision) (*http.Request, error) {
 	// curl -u USER:PASSWORD \
 	//  http://MGMTSERVER/v1/o/ORGNAME/apis/APINAME/revisions/REVNUMBER?format=bundle > bundle.zip
 
@@ -279,7 +310,7 @@ func (s *Deployable) Export(client *ApigeeClient, uriPathElement, assetName stri
 	// append the required query param
 	origURL, err := url.Parse(path)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 	q := origURL.Query()
 	q.Add("format", "bundle")
@@ -288,9 +319,32 @@ func (s *Deployable) Export(client *ApigeeClient, uriPathElement, assetName stri
 
 	req, e := client.NewRequest("GET", path, nil)
 	if e != nil {
-		return "", nil, e
+		return nil, e
 	}
 	req.Header.Del("Accept")
+	return req, nil
+}
+
+// GetRevision retrieves the information about a single revision of an API Proxy or SharedFlow.
+func (s *Deployable) GetRevision(client *ApigeeClient, uriPathElement, assetName string, rev Revision) (*DeployableRevision, *Response, error) {
+	path := path.Join(uriPathElement, assetName, "revisions", fmt.Sprintf("%d", rev))
+	req, e := client.NewRequest("GET", path, nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	returnedRevision := DeployableRevision{}
+	resp, e := client.Do(req, &returnedRevision)
+	if e != nil {
+		return nil, resp, e
+	}
+	return &returnedRevision, resp, e
+}
+
+func (s *Deployable) Export(client *ApigeeClient, uriPathElement, assetName string, rev Revision) (string, *Response, error) {
+	req, e := newExportRequest(client, uriPathElement, assetName, rev)
+	if e != nil {
+		return "", nil, e
+	}
 
 	var assetType string
 	if uriPathElement == "apis" {
@@ -419,3 +473,57 @@ func (s *Deployable) GetDeployments(client *ApigeeClient, uriPathElement, assetN
 	}
 	return &deployments, resp, e
 }
+
+// ExportBundle retrieves a revision of an API Proxy or SharedFlow, in the same
+// way as Export, but parses it in memory rather than writing it to a file.
+func (s *Deployable) ExportBundle(client *ApigeeClient, uriPathElement, assetName string, rev Revision) (*bundle.Bundle, *Response, error) {
+	req, e := newExportRequest(client, uriPathElement, assetName, rev)
+	if e != nil {
+		return nil, nil, e
+	}
+	buf := new(bytes.Buffer)
+	resp, e := client.Do(req, buf)
+	if e != nil {
+		return nil, resp, e
+	}
+	b, e := bundle.ReadZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if e != nil {
+		return nil, resp, e
+	}
+	return b, resp, e
+}
+
+// DiffRevisions compares two revisions of an API Proxy or SharedFlow.
+func (s *Deployable) DiffRevisions(client *ApigeeClient, uriPathElement, assetName string, from, to Revision) (*bundle.Diff, *Response, error) {
+	oldBundle, resp, e := s.ExportBundle(client, uriPathElement, assetName, from)
+	if e != nil {
+		return nil, resp, e
+	}
+	newBundle, resp, e := s.ExportBundle(client, uriPathElement, assetName, to)
+	if e != nil {
+		return nil, resp, e
+	}
+	diff, e := bundle.Compare(oldBundle, newBundle)
+	if e != nil {
+		return nil, resp, e
+	}
+	return diff, resp, e
+}
+
+// DiffSource compares a revision of an API Proxy or SharedFlow with a local
+// bundle. The source can be a directory or a zip file, as for Import.
+func (s *Deployable) DiffSource(client *ApigeeClient, uriPathElement, assetName string, rev Revision, source string) (*bundle.Diff, *Response, error) {
+	newBundle, e := bundle.Load(source)
+	if e != nil {
+		return nil, nil, e
+	}
+	oldBundle, resp, e := s.ExportBundle(client, uriPathElement, assetName, rev)
+	if e != nil {
+		return nil, resp, e
+	}
+	diff, e := bundle.Compare(oldBundle, newBundle)
+	if e != nil {
+		return nil, resp, e
+	}
+	return diff, resp, e
+}
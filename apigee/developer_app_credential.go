@@ -0,0 +1,111 @@
+package apigee
+
+import (
+	"path"
+)
+
+// DeveloperAppCredentialsService is an interface for interfacing with the Apigee Edge Admin API
+// dealing with developerApp credentials/keys.
+type DeveloperAppCredentialsService interface {
+	AddApiProducts(string, string, string, []string) (*Credential, *Response, error)
+	Approve(string, string, string) (*Response, error)
+	ApproveApiProduct(string, string, string, string) (*Response, error)
+	Create(string, string, Credential) (*Credential, *Response, error)
+	Delete(string, string, string) (*Response, error)
+	Get(string, string, string) (*Credential, *Response, error)
+	Revoke(string, string, string) (*Response, error)
+	RevokeApiProduct(string, string, string, string) (*Response, error)
+}
+
+type DeveloperAppCredentialsServiceOp struct {
+	client *ApigeeClient
+}
+
+var _ DeveloperAppCredentialsService = &DeveloperAppCredentialsServiceOp{}
+
+// Create a developer app's consumer key and secret, for example to import a
+// key from another organization. The key has no API products until they are added.
+func (s *DeveloperAppCredentialsServiceOp) Create(developerEmail string, appName string, credential Credential) (*Credential, *Response, error) {
+	uripath := path.Join(developersPath, developerEmail, appPath, appName, keysPath, "create")
+	req, e := s.client.NewRequest("POST", uripath, credential)
+	if e != nil {
+		return nil, nil, e
+	}
+	returnedCredential := Credential{}
+	resp, e := s.client.Do(req, &returnedCredential)
+	if e != nil {
+		return nil, resp, e
+	}
+	return &returnedCredential, resp, e
+}
+
+// Add API products to a developer app's consumer key. Existing products are kept.
+func (s *DeveloperAppCredentialsServiceOp) AddApiProducts(developerEmail string, appName string, consumerKey string, apiProductNames []string) (*Credential, *Response, error) {
+	uripath := path.Join(developersPath, developerEmail, appPath, appName, keysPath, consumerKey)
+	body := struct {
+		ApiProducts []string `json:"apiProducts"`
+	}{apiProductNames}
+	req, e := s.client.NewRequest("POST", uripath, body)
+	if e != nil {
+		return nil, nil, e
+	}
+	returnedCredential := Credential{}
+	resp, e := s.client.Do(req, &returnedCredential)
+	if e != nil {
+		return nil, resp, e
+	}
+	return &returnedCredential, resp, e
+}
+
+// Get information about a developer app's consumer key
+func (s *DeveloperAppCredentialsServiceOp) Get(developerEmail string, appName string, consumerKey string) (*Credential, *Response, error) {
+	uripath := path.Join(developersPath, developerEmail, appPath, appName, keysPath, consumerKey)
+	req, e := s.client.NewRequest("GET", uripath, nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	returnedCredential := Credential{}
+	resp, e := s.client.Do(req, &returnedCredential)
+	if e != nil {
+		return nil, resp, e
+	}
+	return &returnedCredential, resp, e
+}
+
+// Delete a developer app's consumer key
+func (s *DeveloperAppCredentialsServiceOp) Delete(developerEmail string, appName string, consumerKey string) (*Response, error) {
+	uripath := path.Join(developersPath, developerEmail, appPath, appName, keysPath, consumerKey)
+	req, e := s.client.NewRequest("DELETE", uripath, nil)
+	if e != nil {
+		return nil, e
+	}
+	resp, e := s.client.Do(req, nil)
+	if e != nil {
+		return resp, e
+	}
+	return resp, e
+}
+
+// Approve a developer app's consumer key
+func (s *DeveloperAppCredentialsServiceOp) Approve(developerEmail string, appName string, consumerKey string) (*Response, error) {
+	uripath := path.Join(developersPath, developerEmail, appPath, appName, keysPath, consumerKey)
+	return updateCredentialStatus(s.client, uripath, "approve")
+}
+
+// Revoke a developer app's consumer key
+func (s *DeveloperAppCredentialsServiceOp) Revoke(developerEmail string, appName string, consumerKey string) (*Response, error) {
+	uripath := path.Join(developersPath, developerEmail, appPath, appName, keysPath, consumerKey)
+	return updateCredentialStatus(s.client, uripath, "revoke")
+}
+
+// Approve an API product on a developer app's consumer key
+func (s *DeveloperAppCredentialsServiceOp) ApproveApiProduct(developerEmail string, appName string, consumerKey string, apiProductName string) (*Response, error) {
+	uripath := path.Join(developersPath, developerEmail, appPath, appName, keysPath, consumerKey, productsPath, apiProductName)
+	return updateCredentialStatus(s.client, uripath, "approve")
+}
+
+// Revoke an API product on a developer app's consumer key, leaving the product listed on the key
+func (s *DeveloperAppCredentialsServiceOp) RevokeApiProduct(developerEmail string, appName string, consumerKey string, apiProductName string) (*Response, error) {
+	uripath := path.Join(developersPath, developerEmail, appPath, appName, keysPath, consumerKey, productsPath, apiProductName)
+	return updateCredentialStatus(s.client, uripath, "revoke")
+}
@@ -27,7 +27,7 @@ var _ DeveloperAppsService = &DeveloperAppsServiceOp{}
 // DeveloperApp holds information about a registered DeveloperApp.
 type DeveloperApp struct {
 	ApiProducts      []string     `json:"apiProducts,omitempty"`
-	Attributes       []Attribute  `json:"attributes,omitempty"`
+	Attributes       Attributes   `json:"attributes,omitempty"`
 	CallbackUrl      string       `json:"callbackUrl,omitempty"`
 	Credentials      []Credential `json:"credentials,omitempty"`
 	DeveloperId      string       `json:"developerId,omitempty"`
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/url"
 	"path"
+	"strconv"
 )
 
 const developersPath = "developers"
@@ -15,8 +16,12 @@ type DevelopersService interface {
 	GetApps(string) ([]DeveloperApp, *Response, error)
 	Create(Developer) (*Developer, *Response, error)
 	Delete(string) (*Developer, *Response, error)
+	Export(*BulkOptions) ([]BulkDeveloper, error)
 	Get(string) (*Developer, *Response, error)
+	Import([]BulkDeveloper, *BulkOptions) ([]BulkResult, error)
 	List() ([]string, *Response, error)
+	ListExpanded() ([]Developer, *Response, error)
+	ListWithAttribute(string, string) ([]Developer, *Response, error)
 	Revoke(string) (*Response, error)
 	Update(Developer) (*Developer, *Response, error)
 }
@@ -29,16 +34,16 @@ var _ DevelopersService = &DevelopersServiceOp{}
 
 // Developer contains information about a registered Developer within an Edge organization.
 type Developer struct {
-	Apps             []string    `json:"apps,omitempty"`
-	Attributes       []Attribute `json:"attributes,omitempty"`
-	Companies        []string    `json:"companies,omitempty"`
-	Email            string      `json:"email,omitempty"`
-	FirstName        string      `json:"firstName,omitempty"`
-	Id               string      `json:"uuid,omitempty"`
-	LastName         string      `json:"lastName,omitempty"`
-	OrganizationName string      `json:"organizationName,omitempty"`
-	Status           string      `json:"status,omitempty"` // active, inactive, ??
-	UserName         string      `json:"userName,omitempty"`
+	Apps             []string   `json:"apps,omitempty"`
+	Attributes       Attributes `json:"attributes,omitempty"`
+	Companies        []string   `json:"companies,omitempty"`
+	Email            string     `json:"email,omitempty"`
+	FirstName        string     `json:"firstName,omitempty"`
+	Id               string     `json:"uuid,omitempty"`
+	LastName         string     `json:"lastName,omitempty"`
+	OrganizationName string     `json:"organizationName,omitempty"`
+	Status           string     `json:"status,omitempty"` // active, inactive, ??
+	UserName         string     `json:"userName,omitempty"`
 }
 
 func (s *DevelopersServiceOp) Update(dev Developer) (*Developer, *Response, error) {
@@ -109,6 +114,45 @@ func (s *DevelopersServiceOp) List() ([]string, *Response, error) {
 	return namelist, resp, e
 }
 
+// The number of developers requested in each page of ListExpanded; the most Edge allows.
+const developersPageSize = 1000
+
+// ListExpanded retrieves every developer in the organization, with full
+// details, requesting them a page at a time.
+func (s *DevelopersServiceOp) ListExpanded() ([]Developer, *Response, error) {
+	developers := []Developer{}
+	startKey := ""
+	for {
+		q := url.Values{}
+		q.Add("expand", "true")
+		q.Add("count", strconv.Itoa(developersPageSize))
+		if startKey != "" {
+			q.Add("startKey", startKey)
+		}
+		req, e := s.client.NewRequest("GET", developersPath+"?"+q.Encode(), nil)
+		if e != nil {
+			return nil, nil, e
+		}
+		page := struct {
+			Developers []Developer `json:"developer"`
+		}{}
+		resp, e := s.client.Do(req, &page)
+		if e != nil {
+			return nil, resp, e
+		}
+		found := page.Developers
+		// Each page after the first begins with the last developer of the previous page.
+		if startKey != "" && len(found) > 0 && found[0].Email == startKey {
+			found = found[1:]
+		}
+		developers = append(developers, found...)
+		if len(page.Developers) < developersPageSize || len(found) == 0 {
+			return developers, resp, e
+		}
+		startKey = found[len(found)-1].Email
+	}
+}
+
 func (s *DevelopersServiceOp) Get(developerEmailOrId string) (*Developer, *Response, error) {
 	devPath := path.Join(developersPath, developerEmailOrId)
 	req, e := s.client.NewRequest("GET", devPath, nil)
@@ -162,10 +206,15 @@ func (s *DevelopersServiceOp) GetApps(developerEmailOrId string) ([]DeveloperApp
 	if e != nil {
 		return nil, nil, e
 	}
-	apps := make([]DeveloperApp, 0)
+	apps := struct {
+		Apps []DeveloperApp `json:"app"`
+	}{}
 	resp, e := s.client.Do(req, &apps)
 	if e != nil {
 		return nil, resp, e
 	}
-	return apps, resp, e
+	if apps.Apps == nil {
+		apps.Apps = []DeveloperApp{}
+	}
+	return apps.Apps, resp, e
 }
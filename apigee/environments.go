@@ -1,7 +1,9 @@
 package apigee
 
 import (
+	"net/url"
 	"path"
+	"sort"
 )
 
 const environmentsPath = "environments"
@@ -10,7 +12,11 @@ const environmentsPath = "environments"
 // querying Edge environments.
 type EnvironmentsService interface {
 	Get(string) (*Environment, *Response, error)
+	GetDependencyGraph(...string) (*DependencyGraph, error)
+	GetDeployments(string) (*EnvironmentDeployments, *Response, error)
+	GetSharedFlowDeployments(string) (*EnvironmentDeployments, *Response, error)
 	List() ([]string, *Response, error)
+	ListDeployments() ([]EnvironmentDeployments, *Response, error)
 }
 
 type EnvironmentsServiceOp struct {
@@ -29,6 +35,39 @@ type Environment struct {
 	Properties     []Attribute `json:"properties,omitempty"`
 }
 
+// EnvironmentDeployments holds the API Proxies and SharedFlows deployed to an
+// environment, with the state of each deployed revision.
+type EnvironmentDeployments struct {
+	Name        string          `json:"name,omitempty"`
+	Proxies     []DeployedAsset `json:"aPIProxy,omitempty"`
+	SharedFlows []DeployedAsset `json:"sharedFlow,omitempty"`
+}
+
+// DeployedAsset holds the deployed revisions of an API Proxy or SharedFlow.
+type DeployedAsset struct {
+	Name      string               `json:"name,omitempty"`
+	Revisions []RevisionDeployment `json:"revision,omitempty"`
+}
+
+// Proxy returns the deployment of the named API Proxy, or nil if it is not deployed.
+func (d *EnvironmentDeployments) Proxy(name string) *DeployedAsset {
+	return findDeployedAsset(d.Proxies, name)
+}
+
+// SharedFlow returns the deployment of the named SharedFlow, or nil if it is not deployed.
+func (d *EnvironmentDeployments) SharedFlow(name string) *DeployedAsset {
+	return findDeployedAsset(d.SharedFlows, name)
+}
+
+func findDeployedAsset(assets []DeployedAsset, name string) *DeployedAsset {
+	for i := range assets {
+		if assets[i].Name == name {
+			return &assets[i]
+		}
+	}
+	return nil
+}
+
 // List retrieves the list of environment names for the organization referred by the ApigeeClient.
 func (s *EnvironmentsServiceOp) List() ([]string, *Response, error) {
 	req, e := s.client.NewRequest("GET", environmentsPath, nil)
@@ -58,3 +97,67 @@ func (s *EnvironmentsServiceOp) Get(env string) (*Environment, *Response, error)
 	}
 	return &returnedEnv, resp, e
 }
+
+func (s *EnvironmentsServiceOp) getDeployments(env string, sharedFlows bool) (*EnvironmentDeployments, *Response, error) {
+	origURL, e := url.Parse(path.Join(environmentsPath, env, "deployments"))
+	if e != nil {
+		return nil, nil, e
+	}
+	if sharedFlows {
+		q := origURL.Query()
+		q.Add("sharedFlows", "true")
+		origURL.RawQuery = q.Encode()
+	}
+	req, e := s.client.NewRequest("GET", origURL.String(), nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	deployments := EnvironmentDeployments{}
+	resp, e := s.client.Do(req, &deployments)
+	if e != nil {
+		return nil, resp, e
+	}
+	if sharedFlows && len(deployments.SharedFlows) == 0 {
+		// Shared flows are reported under the same key as proxies.
+		deployments.SharedFlows, deployments.Proxies = deployments.Proxies, nil
+	}
+	return &deployments, resp, e
+}
+
+// GetDeployments retrieves every API Proxy deployed to an environment, with the
+// revision, base path and per-server state of each deployment.
+func (s *EnvironmentsServiceOp) GetDeployments(env string) (*EnvironmentDeployments, *Response, error) {
+	return s.getDeployments(env, false)
+}
+
+// GetSharedFlowDeployments retrieves every SharedFlow deployed to an environment,
+// with the revision and per-server state of each deployment.
+func (s *EnvironmentsServiceOp) GetSharedFlowDeployments(env string) (*EnvironmentDeployments, *Response, error) {
+	return s.getDeployments(env, true)
+}
+
+// ListDeployments retrieves the API Proxies and SharedFlows deployed to every
+// environment in the organization, sorted by environment name. The Response is
+// that of the last request made.
+func (s *EnvironmentsServiceOp) ListDeployments() ([]EnvironmentDeployments, *Response, error) {
+	envs, resp, e := s.List()
+	if e != nil {
+		return nil, resp, e
+	}
+	sort.Strings(envs)
+	all := []EnvironmentDeployments{}
+	for _, env := range envs {
+		var proxies, sharedFlows *EnvironmentDeployments
+		proxies, resp, e = s.GetDeployments(env)
+		if e != nil {
+			return nil, resp, e
+		}
+		sharedFlows, resp, e = s.GetSharedFlowDeployments(env)
+		if e != nil {
+			return nil, resp, e
+		}
+		proxies.SharedFlows = sharedFlows.SharedFlows
+		all = append(all, *proxies)
+	}
+	return all, resp, nil
+}
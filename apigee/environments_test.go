@@ -17,3 +17,23 @@ func TestEnvList(t *testing.T) {
 		return
 	}
 }
+
+func TestEnvListDeployments(t *testing.T) {
+	client := NewClientForTesting(t)
+	all, _, e := client.Environments.ListDeployments()
+	if e != nil {
+		t.Errorf("while listing deployments, error:\n%#v\n", e)
+		return
+	}
+	if len(all) <= 0 {
+		t.Errorf("no environments found")
+		return
+	}
+	for _, env := range all {
+		for _, proxy := range env.Proxies {
+			for _, rev := range proxy.Revisions {
+				t.Logf("%s: %s revision %d: %s", env.Name, proxy.Name, rev.Number, rev.State)
+			}
+		}
+	}
+}
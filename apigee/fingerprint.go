@@ -0,0 +1,124 @@
+package apigee
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/brayanhenao/go-apigee-edge/bundle"
+)
+
+const defaultRecentRevisions = 5
+
+// zipFingerprint returns the fingerprint of a zipped bundle, and the name
+// recorded in its descriptor.
+func zipFingerprint(zipfileName string) (string, string, error) {
+	b, e := bundle.Load(zipfileName)
+	if e != nil {
+		return "", "", e
+	}
+	fingerprint, e := b.Fingerprint()
+	if e != nil {
+		return "", "", e
+	}
+	return fingerprint, b.Name(), nil
+}
+
+// recordFingerprint writes a copy of the zip with the fingerprint recorded in
+// the description of the descriptor. It returns the name of the new zip and a
+// function that removes it.
+func recordFingerprint(zipfileName, fingerprint string) (string, func(), error) {
+	b, e := bundle.Load(zipfileName)
+	if e != nil {
+		return "", nil, e
+	}
+	b.Descriptor.SetDescription(bundle.WithFingerprint(b.Descriptor.Description, fingerprint))
+	return writeTempZip(b)
+}
+
+// writeTempZip writes the bundle into a zip in a new temporary directory. It
+// returns the name of the zip and a function that removes it.
+func writeTempZip(b *bundle.Bundle) (string, func(), error) {
+	tempDir, e := ioutil.TempDir("", "go-apigee-")
+	if e != nil {
+		return "", nil, fmt.Errorf("while creating temp dir, error: %#v", e)
+	}
+	cleanup := func() {
+		_ = os.RemoveAll(tempDir)
+	}
+	stampedName := path.Join(tempDir, "bundle.zip")
+	out, e := os.Create(stampedName)
+	if e == nil {
+		e = b.WriteZip(out)
+		if closeError := out.Close(); e == nil {
+			e = closeError
+		}
+	}
+	if e != nil {
+		cleanup()
+		return "", nil, e
+	}
+	return stampedName, cleanup, nil
+}
+
+// findRevisionByFingerprint examines the most recent revisions of an asset and
+// returns the first one with the given fingerprint, or nil if none matches. A
+// revision with a recorded fingerprint is matched on that; any other revision
+// is exported to compute its fingerprint.
+func (s *Deployable) findRevisionByFingerprint(client *ApigeeClient, uriPathElement, assetName, fingerprint string, recent int) (*DeployableRevision, *Response, error) {
+	asset, resp, e := s.Get(client, uriPathElement, assetName)
+	if e != nil {
+		if resp != nil && resp.StatusCode == 404 {
+			// nothing to compare with
+			return nil, resp, nil
+		}
+		return nil, resp, e
+	}
+	revisions := append([]Revision{}, asset.Revisions...)
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i] > revisions[j] })
+	if recent <= 0 {
+		recent = defaultRecentRevisions
+	}
+	if len(revisions) > recent {
+		revisions = revisions[:recent]
+	}
+
+	for _, rev := range revisions {
+		revision, revResp, e := s.revisionWithFingerprint(client, uriPathElement, assetName, rev, fingerprint)
+		resp = revResp
+		if e != nil || revision != nil {
+			return revision, resp, e
+		}
+	}
+	return nil, resp, nil
+}
+
+// revisionWithFingerprint returns the given revision if its content has the
+// fingerprint, and nil otherwise. The revision is exported only when no
+// fingerprint is recorded in its description.
+func (s *Deployable) revisionWithFingerprint(client *ApigeeClient, uriPathElement, assetName string, rev Revision, fingerprint string) (*DeployableRevision, *Response, error) {
+	revision, resp, e := s.GetRevision(client, uriPathElement, assetName, rev)
+	if e != nil {
+		return nil, resp, e
+	}
+	if recorded := bundle.RecordedFingerprint(revision.Description); recorded != "" {
+		if recorded == fingerprint {
+			return revision, resp, nil
+		}
+		return nil, resp, nil
+	}
+	exported, resp, e := s.ExportBundle(client, uriPathElement, assetName, rev)
+	if e != nil {
+		return nil, resp, e
+	}
+	exportedFingerprint, e := exported.Fingerprint()
+	if e != nil {
+		return nil, resp, e
+	}
+	if exportedFingerprint == fingerprint {
+		return revision, resp, nil
+	}
+	return nil, resp, nil
+}
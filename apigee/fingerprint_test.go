@@ -0,0 +1,51 @@
+package apigee
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brayanhenao/go-apigee-edge/bundle"
+)
+
+func TestRecordFingerprint(t *testing.T) {
+	tempDir, e := ioutil.TempDir("", "go-apigee-test-")
+	if e != nil {
+		t.Fatalf("while creating temp dir, error:\n%#v\n", e)
+	}
+	defer os.RemoveAll(tempDir)
+
+	zipfileName := filepath.Join(tempDir, "library.zip")
+	if e := ZipBundle(filepath.Join(proxyBundleDir, "apiproxy-library"), zipfileName, nil); e != nil {
+		t.Fatalf("while zipping, error:\n%#v\n", e)
+	}
+	fingerprint, name, e := zipFingerprint(zipfileName)
+	if e != nil {
+		t.Fatalf("while computing fingerprint, error:\n%#v\n", e)
+	}
+	if name != "library" {
+		t.Errorf("bundle name: got=%q, expected=%q", name, "library")
+	}
+
+	stampedName, cleanup, e := recordFingerprint(zipfileName, fingerprint)
+	if e != nil {
+		t.Fatalf("while recording fingerprint, error:\n%#v\n", e)
+	}
+	defer cleanup()
+
+	stamped, e := bundle.Load(stampedName)
+	if e != nil {
+		t.Fatalf("while loading stamped zip, error:\n%#v\n", e)
+	}
+	if recorded := bundle.RecordedFingerprint(stamped.Descriptor.Description); recorded != fingerprint {
+		t.Errorf("recorded fingerprint: got=%q, expected=%q", recorded, fingerprint)
+	}
+	if !strings.Contains(string(stamped.Descriptor.Content), "<Basepaths>/v1/library</Basepaths>") {
+		t.Errorf("descriptor lost content:\n%s", stamped.Descriptor.Content)
+	}
+	if got, _ := stamped.Fingerprint(); got != fingerprint {
+		t.Errorf("fingerprint changed by recording it: got=%s, expected=%s", got, fingerprint)
+	}
+}
@@ -0,0 +1,126 @@
+package apigee
+
+import (
+	"fmt"
+	"path"
+)
+
+const flowHooksPath = "flowhooks"
+
+// The flow hook points available in every environment.
+const (
+	PreProxyFlowHook   = "PreProxyFlowHook"
+	PostProxyFlowHook  = "PostProxyFlowHook"
+	PreTargetFlowHook  = "PreTargetFlowHook"
+	PostTargetFlowHook = "PostTargetFlowHook"
+)
+
+// FlowHooksService is an interface for interfacing with the Apigee Edge Admin API
+// dealing with the flow hooks of an environment.
+type FlowHooksService interface {
+	Attach(string, string, string, bool) (*FlowHook, *Response, error)
+	Detach(string, string) (*FlowHook, *Response, error)
+	Get(string, string) (*FlowHook, *Response, error)
+	List(string) ([]string, *Response, error)
+}
+
+type FlowHooksServiceOp struct {
+	client *ApigeeClient
+}
+
+var _ FlowHooksService = &FlowHooksServiceOp{}
+
+// FlowHook describes the shared flow attached to a flow hook in an environment.
+// SharedFlow is empty when nothing is attached.
+type FlowHook struct {
+	ContinueOnError bool   `json:"continueOnError"`
+	Description     string `json:"description,omitempty"`
+	SharedFlow      string `json:"sharedFlow,omitempty"`
+}
+
+func validateFlowHook(hook string) error {
+	switch hook {
+	case PreProxyFlowHook, PostProxyFlowHook, PreTargetFlowHook, PostTargetFlowHook:
+		return nil
+	}
+	return fmt.Errorf("unknown flow hook %q", hook)
+}
+
+// List retrieves the names of the flow hook points in an environment.
+func (s *FlowHooksServiceOp) List(env string) ([]string, *Response, error) {
+	path := path.Join(environmentsPath, env, flowHooksPath)
+	req, e := s.client.NewRequest("GET", path, nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	namelist := make([]string, 0)
+	resp, e := s.client.Do(req, &namelist)
+	if e != nil {
+		return nil, resp, e
+	}
+	return namelist, resp, e
+}
+
+// Get retrieves the shared flow attached to a flow hook in an environment.
+func (s *FlowHooksServiceOp) Get(env, hook string) (*FlowHook, *Response, error) {
+	if e := validateFlowHook(hook); e != nil {
+		return nil, nil, e
+	}
+	path := path.Join(environmentsPath, env, flowHooksPath, hook)
+	req, e := s.client.NewRequest("GET", path, nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	flowHook := FlowHook{}
+	resp, e := s.client.Do(req, &flowHook)
+	if e != nil {
+		return nil, resp, e
+	}
+	return &flowHook, resp, e
+}
+
+// Attach attaches a shared flow to a flow hook in an environment, replacing any
+// shared flow already attached. The shared flow must be deployed to the environment.
+func (s *FlowHooksServiceOp) Attach(env, hook, sharedFlow string, continueOnError bool) (*FlowHook, *Response, error) {
+	if e := validateFlowHook(hook); e != nil {
+		return nil, nil, e
+	}
+	deployments, resp, e := s.client.Environments.GetSharedFlowDeployments(env)
+	if e != nil {
+		return nil, resp, e
+	}
+	if deployments.SharedFlow(sharedFlow) == nil {
+		return nil, nil, fmt.Errorf("shared flow %s is not deployed to %s", sharedFlow, env)
+	}
+
+	path := path.Join(environmentsPath, env, flowHooksPath, hook)
+	flowHook := FlowHook{SharedFlow: sharedFlow, ContinueOnError: continueOnError}
+	req, e := s.client.NewRequest("PUT", path, flowHook)
+	if e != nil {
+		return nil, nil, e
+	}
+	returnedFlowHook := FlowHook{}
+	resp, e = s.client.Do(req, &returnedFlowHook)
+	if e != nil {
+		return nil, resp, e
+	}
+	return &returnedFlowHook, resp, e
+}
+
+// Detach removes the shared flow attached to a flow hook in an environment.
+func (s *FlowHooksServiceOp) Detach(env, hook string) (*FlowHook, *Response, error) {
+	if e := validateFlowHook(hook); e != nil {
+		return nil, nil, e
+	}
+	path := path.Join(environmentsPath, env, flowHooksPath, hook)
+	req, e := s.client.NewRequest("DELETE", path, nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	flowHook := FlowHook{}
+	resp, e := s.client.Do(req, &flowHook)
+	if e != nil {
+		return nil, resp, e
+	}
+	return &flowHook, resp, e
+}
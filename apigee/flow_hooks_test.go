@@ -0,0 +1,41 @@
+package apigee
+
+import (
+	"testing"
+)
+
+func TestFlowHookList(t *testing.T) {
+	client := NewClientForTesting(t)
+	env := getEnvironments(t, client)[0]
+	hooks, resp, e := client.FlowHooks.List(env)
+	if e != nil {
+		t.Errorf("while listing flow hooks, error:\n%#v\n", e)
+		return
+	}
+	defer resp.Body.Close()
+	if len(hooks) != 4 {
+		t.Errorf("flow hooks: got=%v", hooks)
+		return
+	}
+	for _, hook := range hooks {
+		flowHook, _, e := client.FlowHooks.Get(env, hook)
+		if e != nil {
+			t.Errorf("while getting flow hook %s, error:\n%#v\n", hook, e)
+			return
+		}
+		t.Logf("%s: %#v", hook, flowHook)
+	}
+}
+
+func TestFlowHookAttachUndeployed(t *testing.T) {
+	client := NewClientForTesting(t)
+	env := getEnvironments(t, client)[0]
+	_, _, e := client.FlowHooks.Attach(env, PreProxyFlowHook, testPrefix+"-"+randomString(8), true)
+	if e == nil {
+		t.Errorf("attaching a shared flow that is not deployed, expected an error")
+	}
+	_, _, e = client.FlowHooks.Get(env, "PreFlowHook")
+	if e == nil {
+		t.Errorf("getting an unknown flow hook, expected an error")
+	}
+}
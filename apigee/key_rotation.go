@@ -0,0 +1,183 @@
+package apigee
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// KeyRotationOptions controls how Rotate replaces a consumer key.
+type KeyRotationOptions struct {
+	// Optional. How long the old key keeps working after the new one is
+	// created, so that clients can switch over. When zero, the old key is
+	// revoked at once.
+	GracePeriod time.Duration
+
+	// Optional. When true, Rotate waits out the grace period and then revokes
+	// the old key. Otherwise Rotate leaves the old key approved, and the caller
+	// must revoke it at KeyRotation.RevokeAfter.
+	Wait bool
+
+	// Optional. The consumer key and secret of the new credential, for example
+	// to import ones generated elsewhere. When both are empty, Edge generates
+	// the new key; when only one is given, the other is random. Edge cannot
+	// give a key it did not generate an expiry, so these cannot be used to
+	// rotate a key that expires.
+	ConsumerKey    string
+	ConsumerSecret string
+}
+
+// KeyRotation describes a consumer key that was replaced by a new one.
+type KeyRotation struct {
+	CompanyName    string
+	AppName        string
+	OldConsumerKey string
+	NewCredential  *Credential
+
+	// When the old key should be revoked, and whether it has been.
+	RevokeAfter   time.Time
+	OldKeyRevoked bool
+}
+
+const keyAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// randomKey returns a random alphanumeric string of the given length, like the
+// consumer keys and secrets that Edge generates.
+func randomKey(length int) (string, error) {
+	b := make([]byte, length)
+	max := big.NewInt(int64(len(keyAlphabet)))
+	for i := range b {
+		n, e := rand.Int(rand.Reader, max)
+		if e != nil {
+			return "", e
+		}
+		b[i] = keyAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
+
+// Rotate replaces a company app's consumer key with a new one that has the same
+// API products, attributes and expiry. Products approved on the old key are
+// approved on the new key. The old key is revoked at once, or after the grace
+// period in opts when Wait is set. Edge cannot change the expiry of an existing
+// key, so with a grace period and no Wait, Rotate does not revoke or schedule
+// anything: the old key stays valid until the caller revokes it at RevokeAfter.
+// If the new key cannot be set up, it is deleted and the old key is left
+// untouched.
+func (s *CompanyAppCredentialsServiceOp) Rotate(companyName string, appName string, consumerKey string, opts *KeyRotationOptions) (*KeyRotation, *Response, error) {
+	if opts == nil {
+		opts = &KeyRotationOptions{}
+	}
+	old, resp, e := s.Get(companyName, appName, consumerKey)
+	if e != nil {
+		return nil, resp, e
+	}
+
+	created, resp, e := s.createRotated(companyName, appName, old, opts)
+	if e != nil {
+		return nil, resp, fmt.Errorf("while creating new key, error: %v", e)
+	}
+	newKey := created.ConsumerKey
+
+	resp, e = s.copyCredential(companyName, appName, old, newKey)
+	if e != nil {
+		if _, deleteError := s.Delete(companyName, appName, newKey); deleteError != nil {
+			return nil, resp, fmt.Errorf("%v; and while deleting the new key %s, error: %v", e, newKey, deleteError)
+		}
+		return nil, resp, e
+	}
+	final, resp, e := s.Get(companyName, appName, newKey)
+	if e != nil {
+		return nil, resp, e
+	}
+
+	rotation := &KeyRotation{
+		CompanyName:    companyName,
+		AppName:        appName,
+		OldConsumerKey: consumerKey,
+		NewCredential:  final,
+		RevokeAfter:    time.Now().Add(opts.GracePeriod),
+	}
+	if opts.GracePeriod > 0 && !opts.Wait {
+		return rotation, resp, nil
+	}
+	time.Sleep(opts.GracePeriod)
+	resp, e = s.Revoke(companyName, appName, consumerKey)
+	if e != nil {
+		return rotation, resp, fmt.Errorf("while revoking old key, error: %v", e)
+	}
+	rotation.OldKeyRevoked = true
+	return rotation, resp, nil
+}
+
+// createRotated creates the key that replaces old. Edge generates it, with the
+// products of old and the same expiry, unless opts gives the key or secret.
+func (s *CompanyAppCredentialsServiceOp) createRotated(companyName string, appName string, old *Credential, opts *KeyRotationOptions) (*Credential, *Response, error) {
+	now := time.Now()
+	if old.ExpiresBefore(now) {
+		return nil, nil, fmt.Errorf("key %s has expired", old.ConsumerKey)
+	}
+	expiresIn := remainingLifetime(old, now)
+	if opts.ConsumerKey == "" && opts.ConsumerSecret == "" {
+		products := []string{}
+		for _, p := range old.ApiProducts {
+			products = append(products, p.ApiProduct)
+		}
+		return s.Generate(companyName, appName, products, expiresIn)
+	}
+	if expiresIn > 0 {
+		return nil, nil, fmt.Errorf("key %s expires, and Edge cannot give an expiry to a key it did not generate", old.ConsumerKey)
+	}
+	newCredential := Credential{ConsumerKey: opts.ConsumerKey, ConsumerSecret: opts.ConsumerSecret}
+	var e error
+	if newCredential.ConsumerKey == "" {
+		if newCredential.ConsumerKey, e = randomKey(32); e != nil {
+			return nil, nil, e
+		}
+	}
+	if newCredential.ConsumerSecret == "" {
+		if newCredential.ConsumerSecret, e = randomKey(16); e != nil {
+			return nil, nil, e
+		}
+	}
+	return s.Create(companyName, appName, newCredential)
+}
+
+// remainingLifetime returns how long after now the credential expires, or zero
+// when it never expires.
+func remainingLifetime(c *Credential, now time.Time) time.Duration {
+	if !c.Expires() {
+		return 0
+	}
+	return c.ExpiresAt.Sub(now)
+}
+
+// copyCredential gives the key newKey the products, product approvals and
+// attributes of the credential old.
+func (s *CompanyAppCredentialsServiceOp) copyCredential(companyName string, appName string, old *Credential, newKey string) (*Response, error) {
+	products := []string{}
+	for _, p := range old.ApiProducts {
+		products = append(products, p.ApiProduct)
+	}
+	if len(products) > 0 {
+		if _, resp, e := s.AddApiProducts(companyName, appName, newKey, products); e != nil {
+			return resp, fmt.Errorf("while adding products to new key, error: %v", e)
+		}
+	}
+	for _, p := range old.ApiProducts {
+		if p.Status != "approved" {
+			continue
+		}
+		if resp, e := s.ApproveApiProduct(companyName, appName, newKey, p.ApiProduct); e != nil {
+			return resp, fmt.Errorf("while approving %s on new key, error: %v", p.ApiProduct, e)
+		}
+	}
+	if len(old.Attributes) > 0 {
+		owner := CompanyAppKeyAttributes(companyName, appName, newKey)
+		if _, resp, e := s.client.Attributes.Replace(owner, old.Attributes); e != nil {
+			return resp, fmt.Errorf("while copying attributes to new key, error: %v", e)
+		}
+	}
+	return nil, nil
+}
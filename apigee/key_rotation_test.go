@@ -0,0 +1,98 @@
+package apigee
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRandomKey(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		key, e := randomKey(32)
+		if e != nil {
+			t.Fatalf("error=%v", e)
+		}
+		if len(key) != 32 || seen[key] {
+			t.Errorf("got=%q", key)
+		}
+		for _, c := range key {
+			if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+				t.Errorf("got=%q, not alphanumeric", key)
+				break
+			}
+		}
+		seen[key] = true
+	}
+}
+
+func TestRemainingLifetime(t *testing.T) {
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	tt := []struct {
+		desc      string
+		expiresAt time.Time
+		expected  time.Duration
+	}{
+		{"never expires", time.Unix(0, 0).Add(-time.Millisecond), 0},
+		{"expires later", now.Add(48 * time.Hour), 48 * time.Hour},
+	}
+	for _, tc := range tt {
+		c := &Credential{ExpiresAt: Timestamp{tc.expiresAt}}
+		if got := remainingLifetime(c, now); got != tc.expected {
+			t.Errorf("%s: got=%v, expected=%v", tc.desc, got, tc.expected)
+		}
+	}
+}
+
+func TestGeneratedCredential(t *testing.T) {
+	before := []Credential{{ConsumerKey: "a"}, {ConsumerKey: "b"}}
+	if got := generatedCredential(before, append(before, Credential{ConsumerKey: "c"})); got == nil || got.ConsumerKey != "c" {
+		t.Errorf("new key: got=%v", got)
+	}
+	if got := generatedCredential(before, before); got != nil {
+		t.Errorf("no new key: got=%v", got)
+	}
+}
+
+func TestCompanyAppKeyRotate(t *testing.T) {
+	client := NewClientForTesting(t)
+	name := testPrefix + randomString(7)
+	_, _, e := client.Companies.Create(Company{Name: name, DisplayName: name})
+	if e != nil {
+		t.Errorf("while creating company, error:\n%#v\n", e)
+		return
+	}
+	defer client.Companies.Delete(name)
+
+	app, _, e := client.CompanyApps.Create(name, CompanyApp{Name: name + "-app", Attributes: Attributes{{Name: "tier", Value: "gold"}}})
+	if e != nil {
+		t.Errorf("while creating company app, error:\n%#v\n", e)
+		return
+	}
+	defer client.CompanyApps.Delete(name, app.Name)
+	if len(app.Credentials) == 0 {
+		t.Errorf("created app has no credentials")
+		return
+	}
+	oldKey := app.Credentials[0].ConsumerKey
+	owner := CompanyAppKeyAttributes(name, app.Name, oldKey)
+	if _, _, e := client.Attributes.Set(owner, "rotated", "no"); e != nil {
+		t.Errorf("while setting key attribute, error:\n%#v\n", e)
+		return
+	}
+
+	rotation, _, e := client.CompanyAppCredentials.Rotate(name, app.Name, oldKey, nil)
+	if e != nil {
+		t.Errorf("while rotating key, error:\n%#v\n", e)
+		return
+	}
+	if !rotation.OldKeyRevoked || rotation.NewCredential.ConsumerKey == oldKey {
+		t.Errorf("Rotate: got=%#v", rotation)
+	}
+	if v, _ := rotation.NewCredential.Attributes.Get("rotated"); v != "no" {
+		t.Errorf("attributes not copied: got=%v", rotation.NewCredential.Attributes)
+	}
+	old, _, e := client.CompanyAppCredentials.Get(name, app.Name, oldKey)
+	if e != nil || old.Status != "revoked" {
+		t.Errorf("old key: got=%v, error=%v", old, e)
+	}
+}
@@ -0,0 +1,176 @@
+package apigee
+
+import (
+	"fmt"
+	"sort"
+)
+
+// AppAccess describes an app, owned by a developer or a company, together with
+// those of its credentials that include one of the API Products looked up.
+// Credentials are included whatever their status, or the status of the
+// product on them, so that revoked and pending access can be reported too.
+type AppAccess struct {
+	DeveloperId    string       `json:"developerId,omitempty"`
+	DeveloperEmail string       `json:"developerEmail,omitempty"`
+	CompanyName    string       `json:"companyName,omitempty"`
+	AppName        string       `json:"appName"`
+	AppId          string       `json:"appId,omitempty"`
+	AppStatus      string       `json:"appStatus,omitempty"`
+	Credentials    []Credential `json:"credentials"`
+}
+
+// Owner returns the email of the developer, or the name of the company, that
+// owns the app.
+func (a AppAccess) Owner() string {
+	if a.CompanyName != "" {
+		return a.CompanyName
+	}
+	return a.DeveloperEmail
+}
+
+// ProxyAccess reports who has access to an API Proxy: the API Products that list
+// it, the apps with credentials for those products, and the owners of those apps.
+type ProxyAccess struct {
+	Proxy      string      `json:"proxy"`
+	Products   []string    `json:"products"`
+	Apps       []AppAccess `json:"apps"`
+	Developers []string    `json:"developers"`
+	Companies  []string    `json:"companies"`
+}
+
+// ListForProxy returns the API Products that list the named API Proxy.
+func (s *ProductsServiceOp) ListForProxy(proxyName string) ([]ApiProduct, *Response, error) {
+	all, resp, e := s.ListExpanded()
+	if e != nil {
+		return nil, resp, fmt.Errorf("while listing API Products, error: %v", e)
+	}
+	products := []ApiProduct{}
+	for _, p := range all {
+		for _, proxy := range p.Proxies {
+			if proxy == proxyName {
+				products = append(products, p)
+				break
+			}
+		}
+	}
+	return products, resp, nil
+}
+
+// ListForProducts returns the developer and company apps with a credential that
+// includes any of the named API Products, sorted by owner and app name. Every
+// app in the organization is examined.
+func (s *AppsServiceOp) ListForProducts(productNames ...string) ([]AppAccess, *Response, error) {
+	all, resp, e := s.ListAll(&AppsListOptions{IncludeCredentials: true})
+	if e != nil {
+		return nil, resp, fmt.Errorf("while listing apps, error: %v", e)
+	}
+	apps := filterAppsByProduct(appAccessFrom(all), productNames)
+	needEmails := false
+	for _, app := range apps {
+		needEmails = needEmails || app.CompanyName == ""
+	}
+	if needEmails {
+		developers, devResp, e := s.client.Developers.ListExpanded()
+		if e != nil {
+			return nil, devResp, fmt.Errorf("while listing developers, error: %v", e)
+		}
+		emails := map[string]string{}
+		for _, d := range developers {
+			emails[d.Id] = d.Email
+		}
+		for i := range apps {
+			apps[i].DeveloperEmail = emails[apps[i].DeveloperId]
+		}
+		resp = devResp
+	}
+	sort.Slice(apps, func(i, j int) bool {
+		if apps[i].Owner() != apps[j].Owner() {
+			return apps[i].Owner() < apps[j].Owner()
+		}
+		return apps[i].AppName < apps[j].AppName
+	})
+	return apps, resp, nil
+}
+
+// GetAccess reports the API Products listing the named API Proxy, the apps with
+// credentials for them, and the developers and companies owning those apps.
+func (s *ProxiesServiceOp) GetAccess(proxyName string) (*ProxyAccess, *Response, error) {
+	products, resp, e := s.client.Products.ListForProxy(proxyName)
+	if e != nil {
+		return nil, resp, e
+	}
+	access := &ProxyAccess{Proxy: proxyName, Products: []string{}, Apps: []AppAccess{}, Developers: []string{}, Companies: []string{}}
+	for _, p := range products {
+		access.Products = append(access.Products, p.Name)
+	}
+	if len(products) == 0 {
+		return access, resp, nil
+	}
+	access.Apps, resp, e = s.client.Apps.ListForProducts(access.Products...)
+	if e != nil {
+		return nil, resp, e
+	}
+	developers := map[string]bool{}
+	companies := map[string]bool{}
+	for _, app := range access.Apps {
+		if app.CompanyName != "" {
+			companies[app.CompanyName] = true
+		} else {
+			developers[app.DeveloperEmail] = true
+		}
+	}
+	access.Developers = sortedNames(developers)
+	access.Companies = sortedNames(companies)
+	return access, resp, nil
+}
+
+func sortedNames(set map[string]bool) []string {
+	names := []string{}
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// appAccessFrom converts apps listed across the organization to AppAccess.
+// Developer apps carry only the developer id; the email is filled in later.
+func appAccessFrom(apps []App) []AppAccess {
+	access := []AppAccess{}
+	for _, app := range apps {
+		a := AppAccess{AppName: app.Name, AppId: app.AppId, AppStatus: app.Status, Credentials: app.Credentials}
+		if app.IsCompanyApp() {
+			a.CompanyName = app.CompanyName
+		} else {
+			a.DeveloperId = app.DeveloperId
+		}
+		access = append(access, a)
+	}
+	return access
+}
+
+// filterAppsByProduct keeps the apps with a credential that includes one of the
+// products, and within them only those credentials.
+func filterAppsByProduct(apps []AppAccess, productNames []string) []AppAccess {
+	wanted := map[string]bool{}
+	for _, name := range productNames {
+		wanted[name] = true
+	}
+	matched := []AppAccess{}
+	for _, app := range apps {
+		credentials := []Credential{}
+		for _, c := range app.Credentials {
+			for _, p := range c.ApiProducts {
+				if wanted[p.ApiProduct] {
+					credentials = append(credentials, c)
+					break
+				}
+			}
+		}
+		if len(credentials) > 0 {
+			app.Credentials = credentials
+			matched = append(matched, app)
+		}
+	}
+	return matched
+}
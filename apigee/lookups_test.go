@@ -0,0 +1,80 @@
+package apigee
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterAppsByProduct(t *testing.T) {
+	credential := func(key string, products ...string) Credential {
+		c := Credential{ConsumerKey: key}
+		for _, p := range products {
+			c.ApiProducts = append(c.ApiProducts, CredentialApiProduct{ApiProduct: p, Status: "approved"})
+		}
+		return c
+	}
+	apps := []AppAccess{
+		{DeveloperEmail: "a@example.com", AppName: "one", Credentials: []Credential{credential("k1", "gold"), credential("k2", "silver")}},
+		{DeveloperEmail: "b@example.com", AppName: "two", Credentials: []Credential{credential("k3", "bronze")}},
+		{CompanyName: "acme", AppName: "three", Credentials: []Credential{credential("k4", "bronze", "silver")}},
+		{CompanyName: "acme", AppName: "four"},
+	}
+	tt := []struct {
+		desc     string
+		products []string
+		expected map[string][]string
+	}{
+		{"one product", []string{"silver"}, map[string][]string{"one": {"k2"}, "three": {"k4"}}},
+		{"two products", []string{"gold", "bronze"}, map[string][]string{"one": {"k1"}, "two": {"k3"}, "three": {"k4"}}},
+		{"no match", []string{"platinum"}, map[string][]string{}},
+	}
+	for _, test := range tt {
+		got := map[string][]string{}
+		for _, app := range filterAppsByProduct(apps, test.products) {
+			for _, c := range app.Credentials {
+				got[app.AppName] = append(got[app.AppName], c.ConsumerKey)
+			}
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: got=%v, expected=%v", test.desc, got, test.expected)
+		}
+	}
+	if len(apps[0].Credentials) != 2 {
+		t.Errorf("filtering modified the input")
+	}
+}
+
+func TestAppAccessFrom(t *testing.T) {
+	credentials := []Credential{{ConsumerKey: "k1"}}
+	apps := []App{
+		{Name: "one", AppId: "id1", Status: "approved", DeveloperId: "dev1", Credentials: credentials},
+		{Name: "two", AppId: "id2", Status: "revoked", CompanyName: "acme"},
+	}
+	got := appAccessFrom(apps)
+	expected := []AppAccess{
+		{DeveloperId: "dev1", AppName: "one", AppId: "id1", AppStatus: "approved", Credentials: credentials},
+		{CompanyName: "acme", AppName: "two", AppId: "id2", AppStatus: "revoked"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got=%+v, expected=%+v", got, expected)
+	}
+}
+
+func TestGetProxyAccess(t *testing.T) {
+	client := NewClientForTesting(t)
+	namelist, _, e := client.Proxies.List()
+	if e != nil {
+		t.Errorf("while listing proxies, error:\n%#v\n", e)
+		return
+	}
+	if len(namelist) == 0 {
+		t.Errorf("no proxies found")
+		return
+	}
+	access, _, e := client.Proxies.GetAccess(namelist[0])
+	if e != nil {
+		t.Errorf("while looking up developers, error:\n%#v\n", e)
+		return
+	}
+	t.Logf("%s: products=%v developers=%v companies=%v", access.Proxy, access.Products, access.Developers, access.Companies)
+}
@@ -0,0 +1,121 @@
+package apigee
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QuotaTimeUnit is the unit of the interval of an API Product quota.
+type QuotaTimeUnit string
+
+const (
+	QuotaMinute QuotaTimeUnit = "minute"
+	QuotaHour   QuotaTimeUnit = "hour"
+	QuotaDay    QuotaTimeUnit = "day"
+	QuotaMonth  QuotaTimeUnit = "month"
+)
+
+// Duration returns the length of one unit. A month is taken to be 30 days.
+func (u QuotaTimeUnit) Duration() time.Duration {
+	switch u {
+	case QuotaMinute:
+		return time.Minute
+	case QuotaHour:
+		return time.Hour
+	case QuotaDay:
+		return 24 * time.Hour
+	case QuotaMonth:
+		return 30 * 24 * time.Hour
+	}
+	return 0
+}
+
+// ProductQuota is the quota of an API Product: Limit requests per Interval
+// TimeUnits, eg 100 per 1 minute. In ApiProduct it is held as the strings
+// Quota, QuotaInterval and QuotaTimeUnit; use the ProductQuota and
+// SetProductQuota methods of ApiProduct to convert.
+type ProductQuota struct {
+	Limit    int
+	Interval int
+	TimeUnit QuotaTimeUnit
+}
+
+func (q ProductQuota) String() string {
+	return fmt.Sprintf("%d per %d %s", q.Limit, q.Interval, q.TimeUnit)
+}
+
+// Validate returns an error unless the limit and interval are positive and the
+// time unit is one that Edge understands.
+func (q ProductQuota) Validate() error {
+	if q.Limit <= 0 {
+		return fmt.Errorf("quota limit must be positive, got %d", q.Limit)
+	}
+	if q.Interval <= 0 {
+		return fmt.Errorf("quota interval must be positive, got %d", q.Interval)
+	}
+	if q.TimeUnit.Duration() == 0 {
+		return fmt.Errorf("quota time unit must be minute, hour, day or month, got %q", q.TimeUnit)
+	}
+	return nil
+}
+
+// Window returns the length of the quota interval.
+func (q ProductQuota) Window() time.Duration {
+	return time.Duration(q.Interval) * q.TimeUnit.Duration()
+}
+
+// RatePer returns the average number of requests the quota allows in the given
+// duration, eg RatePer(time.Second) for requests per second.
+func (q ProductQuota) RatePer(d time.Duration) float64 {
+	window := q.Window()
+	if window == 0 {
+		return 0
+	}
+	return float64(q.Limit) * float64(d) / float64(window)
+}
+
+// ProductQuota returns the quota of the product, or nil if it has none. It is
+// an error for the quota to be partly set or invalid.
+func (p *ApiProduct) ProductQuota() (*ProductQuota, error) {
+	if p.Quota == "" && p.QuotaInterval == "" && p.QuotaTimeUnit == "" {
+		return nil, nil
+	}
+	limit, e := strconv.Atoi(strings.TrimSpace(p.Quota))
+	if e != nil {
+		return nil, fmt.Errorf("quota %q is not a number", p.Quota)
+	}
+	interval, e := strconv.Atoi(strings.TrimSpace(p.QuotaInterval))
+	if e != nil {
+		return nil, fmt.Errorf("quota interval %q is not a number", p.QuotaInterval)
+	}
+	q := &ProductQuota{
+		Limit:    limit,
+		Interval: interval,
+		TimeUnit: QuotaTimeUnit(strings.ToLower(strings.TrimSpace(p.QuotaTimeUnit))),
+	}
+	if e := q.Validate(); e != nil {
+		return nil, e
+	}
+	return q, nil
+}
+
+// SetProductQuota sets the quota of the product, or removes it if q is nil.
+func (p *ApiProduct) SetProductQuota(q *ProductQuota) {
+	if q == nil {
+		p.Quota, p.QuotaInterval, p.QuotaTimeUnit = "", "", ""
+		return
+	}
+	p.Quota = strconv.Itoa(q.Limit)
+	p.QuotaInterval = strconv.Itoa(q.Interval)
+	p.QuotaTimeUnit = string(q.TimeUnit)
+}
+
+// validateProductQuota checks the quota of a product about to be sent to Edge.
+func validateProductQuota(p *ApiProduct) error {
+	if _, e := p.ProductQuota(); e != nil {
+		return fmt.Errorf("invalid quota for API Product %s: %v", p.Name, e)
+	}
+	return nil
+}
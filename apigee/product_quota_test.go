@@ -0,0 +1,70 @@
+package apigee
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProductQuota(t *testing.T) {
+	tt := []struct {
+		desc     string
+		product  ApiProduct
+		expected *ProductQuota
+		valid    bool
+	}{
+		{"no quota", ApiProduct{}, nil, true},
+		{"per minute", ApiProduct{Quota: "100", QuotaInterval: "1", QuotaTimeUnit: "minute"}, &ProductQuota{100, 1, QuotaMinute}, true},
+		{"mixed case unit", ApiProduct{Quota: "5", QuotaInterval: "2", QuotaTimeUnit: "Month"}, &ProductQuota{5, 2, QuotaMonth}, true},
+		{"zero interval", ApiProduct{Quota: "100", QuotaInterval: "0", QuotaTimeUnit: "minute"}, nil, false},
+		{"unknown unit", ApiProduct{Quota: "100", QuotaInterval: "1", QuotaTimeUnit: "fortnight"}, nil, false},
+		{"partly set", ApiProduct{Quota: "100"}, nil, false},
+		{"not a number", ApiProduct{Quota: "lots", QuotaInterval: "1", QuotaTimeUnit: "day"}, nil, false},
+		{"negative limit", ApiProduct{Quota: "-1", QuotaInterval: "1", QuotaTimeUnit: "day"}, nil, false},
+	}
+	for _, test := range tt {
+		got, e := test.product.ProductQuota()
+		if (e == nil) != test.valid {
+			t.Errorf("%s: error=%v, expected valid=%t", test.desc, e, test.valid)
+			continue
+		}
+		if (got == nil) != (test.expected == nil) || (got != nil && *got != *test.expected) {
+			t.Errorf("%s: got=%v, expected=%v", test.desc, got, test.expected)
+		}
+		if (validateProductQuota(&test.product) == nil) != test.valid {
+			t.Errorf("%s: validateProductQuota disagrees", test.desc)
+		}
+	}
+}
+
+func TestSetProductQuota(t *testing.T) {
+	p := ApiProduct{}
+	q := &ProductQuota{Limit: 1000, Interval: 3, TimeUnit: QuotaHour}
+	p.SetProductQuota(q)
+	if p.Quota != "1000" || p.QuotaInterval != "3" || p.QuotaTimeUnit != "hour" {
+		t.Errorf("got=%#v", p)
+	}
+	got, e := p.ProductQuota()
+	if e != nil || *got != *q {
+		t.Errorf("round trip: got=%v, error=%v", got, e)
+	}
+	p.SetProductQuota(nil)
+	if got, e := p.ProductQuota(); got != nil || e != nil {
+		t.Errorf("after removing: got=%v, error=%v", got, e)
+	}
+}
+
+func TestProductQuotaRate(t *testing.T) {
+	q := ProductQuota{Limit: 7200, Interval: 2, TimeUnit: QuotaHour}
+	if got := q.Window(); got != 2*time.Hour {
+		t.Errorf("Window: got=%v", got)
+	}
+	if got := q.RatePer(time.Second); got != 1 {
+		t.Errorf("RatePer(second): got=%v, expected=1", got)
+	}
+	if got := q.RatePer(time.Minute); got != 60 {
+		t.Errorf("RatePer(minute): got=%v, expected=60", got)
+	}
+	if got := q.String(); got != "7200 per 2 hour" {
+		t.Errorf("String: got=%q", got)
+	}
+}
@@ -2,7 +2,9 @@ package apigee
 
 import (
 	"errors"
+	"fmt"
 	"path"
+	"time"
 )
 
 const productsPath = "apiproducts"
@@ -14,7 +16,17 @@ type ProductsService interface {
 	Delete(string) (*ApiProduct, *Response, error)
 	Get(string) (*ApiProduct, *Response, error)
 	List() ([]string, *Response, error)
+	ListExpanded() ([]ApiProduct, *Response, error)
+	ListForProxy(string) ([]ApiProduct, *Response, error)
 	Update(ApiProduct) (*ApiProduct, *Response, error)
+	Modify(string, func(*ApiProduct) error) (*ApiProduct, *Response, error)
+	AddProxy(string, string) (*ApiProduct, *Response, error)
+	RemoveProxy(string, string) (*ApiProduct, *Response, error)
+	AddApiResource(string, string) (*ApiProduct, *Response, error)
+	RemoveApiResource(string, string) (*ApiProduct, *Response, error)
+	AddEnvironment(string, string) (*ApiProduct, *Response, error)
+	RemoveEnvironment(string, string) (*ApiProduct, *Response, error)
+	SetQuota(string, *ProductQuota) (*ApiProduct, *Response, error)
 }
 
 type ProductsServiceOp struct {
@@ -25,22 +37,22 @@ var _ ProductsService = &ProductsServiceOp{}
 
 // ApiProduct contains information about an API Product within an Edge organization.
 type ApiProduct struct {
-	ApiResources   []string    `json:"apiResources,omitempty"`
-	ApprovalType   string      `json:"approvalType,omitempty"`
-	Attributes     []Attribute `json:"attributes,omitempty"`
-	CreatedAt      Timestamp   `json:"createdAt,omitempty"`
-	CreatedBy      string      `json:"createdBy,omitempty"`
-	Description    string      `json:"description,omitempty"`
-	DisplayName    string      `json:"displayName,omitempty"`
-	Environments   []string    `json:"environments,omitempty"`
-	LastModifiedAt Timestamp   `json:"lastModifiedAt,omitempty"`
-	LastModifiedBy string      `json:"lastModifiedBy,omitempty"`
-	Name           string      `json:"name,omitempty"`
-	Proxies        []string    `json:"proxies,omitempty"`
-	Quota          string      `json:"quota,omitempty"`
-	QuotaInterval  string      `json:"quotaInterval,omitempty"`
-	QuotaTimeUnit  string      `json:"quotaTimeUnit,omitempty"`
-	Scopes         []string    `json:"scopes,omitempty"`
+	ApiResources   []string   `json:"apiResources,omitempty"`
+	ApprovalType   string     `json:"approvalType,omitempty"`
+	Attributes     Attributes `json:"attributes,omitempty"`
+	CreatedAt      Timestamp  `json:"createdAt,omitempty"`
+	CreatedBy      string     `json:"createdBy,omitempty"`
+	Description    string     `json:"description,omitempty"`
+	DisplayName    string     `json:"displayName,omitempty"`
+	Environments   []string   `json:"environments,omitempty"`
+	LastModifiedAt Timestamp  `json:"lastModifiedAt,omitempty"`
+	LastModifiedBy string     `json:"lastModifiedBy,omitempty"`
+	Name           string     `json:"name,omitempty"`
+	Proxies        []string   `json:"proxies,omitempty"`
+	Quota          string     `json:"quota,omitempty"`
+	QuotaInterval  string     `json:"quotaInterval,omitempty"`
+	QuotaTimeUnit  string     `json:"quotaTimeUnit,omitempty"`
+	Scopes         []string   `json:"scopes,omitempty"`
 }
 
 func reallyUpdateProduct(s ProductsServiceOp, product ApiProduct) (*ApiProduct, *Response, error) {
@@ -57,6 +69,11 @@ func reallyUpdateProduct(s ProductsServiceOp, product ApiProduct) (*ApiProduct,
 	return &returnedProduct, resp, e
 }
 
+// Update replaces an API Product. ApprovalType, DisplayName and Environments are
+// filled in from the existing product when omitted, but other omitted fields,
+// like Proxies and Attributes, are cleared. It is an error for the quota to be
+// partly set or invalid. Use Modify to change some fields
+// while preserving the rest.
 func (s *ProductsServiceOp) Update(product ApiProduct) (*ApiProduct, *Response, error) {
 	if product.Name == "" {
 		return nil, nil, errors.New("must specify Name of ApiProduct to update")
@@ -84,10 +101,18 @@ func (s *ProductsServiceOp) Update(product ApiProduct) (*ApiProduct, *Response,
 	// If the caller has omitted the list of api proxies from the product,
 	// this call will update the product to have no proxies!  Likewise
 	// attributes.
+	if e := validateProductQuota(&product); e != nil {
+		return nil, nil, e
+	}
 	return reallyUpdateProduct(*s, product)
 }
 
+// Create creates an API Product. It is an error for the quota to be partly set
+// or invalid.
 func (s *ProductsServiceOp) Create(product ApiProduct) (*ApiProduct, *Response, error) {
+	if e := validateProductQuota(&product); e != nil {
+		return nil, nil, e
+	}
 	req, e := s.client.NewRequest("POST", productsPath, product)
 	if e != nil {
 		return nil, nil, e
@@ -128,6 +153,25 @@ func (s *ProductsServiceOp) List() ([]string, *Response, error) {
 	return namelist, resp, e
 }
 
+// ListExpanded retrieves every API Product in the organization, with full details.
+func (s *ProductsServiceOp) ListExpanded() ([]ApiProduct, *Response, error) {
+	req, e := s.client.NewRequest("GET", productsPath+"?expand=true", nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	products := struct {
+		ApiProducts []ApiProduct `json:"apiProduct"`
+	}{}
+	resp, e := s.client.Do(req, &products)
+	if e != nil {
+		return nil, resp, e
+	}
+	if products.ApiProducts == nil {
+		products.ApiProducts = []ApiProduct{}
+	}
+	return products.ApiProducts, resp, e
+}
+
 // Get retrieves the information about an API Product in an organization, information including
 // the list of API Proxies, the scopes, the quota, and other attributes.
 func (s *ProductsServiceOp) Get(productName string) (*ApiProduct, *Response, error) {
@@ -143,3 +187,142 @@ func (s *ProductsServiceOp) Get(productName string) (*ApiProduct, *Response, err
 	}
 	return &returnedProduct, resp, e
 }
+
+// The number of times Modify fetches and modifies a product that keeps being
+// changed by someone else before giving up.
+const maxProductModifyAttempts = 5
+
+// ConcurrentModificationError is returned by Modify when the product was
+// changed by someone else on every attempt.
+type ConcurrentModificationError struct {
+	Name     string
+	Attempts int
+}
+
+func (e *ConcurrentModificationError) Error() string {
+	return fmt.Sprintf("API Product %s was modified concurrently on each of %d attempts", e.Name, e.Attempts)
+}
+
+// Modify fetches the named API Product, applies mutate to it, and posts the
+// full product back, so that fields not touched by mutate are preserved. If
+// the product was modified by someone else in the meantime, as shown by its
+// LastModifiedAt, the product is fetched again and mutate is reapplied. The
+// Admin API has no conditional update, so a change made between the final
+// check and the post can still be lost; the window is small. If mutate returns
+// an error, nothing is posted and the error is returned. The quota is checked
+// as for Update only when mutate changes it.
+func (s *ProductsServiceOp) Modify(productName string, mutate func(*ApiProduct) error) (*ApiProduct, *Response, error) {
+	for attempt := 1; ; attempt++ {
+		product, resp, e := s.Get(productName)
+		if e != nil {
+			return nil, resp, e
+		}
+		lastModified := product.LastModifiedAt
+		quota := [3]string{product.Quota, product.QuotaInterval, product.QuotaTimeUnit}
+		if e := mutate(product); e != nil {
+			return nil, nil, e
+		}
+		product.Name = productName
+		// A product may already hold a quota Edge accepted but that is not
+		// valid; check the quota only when it is being changed.
+		if quota != [3]string{product.Quota, product.QuotaInterval, product.QuotaTimeUnit} {
+			if e := validateProductQuota(product); e != nil {
+				return nil, nil, e
+			}
+		}
+
+		current, resp, e := s.Get(productName)
+		if e != nil {
+			return nil, resp, e
+		}
+		if current.LastModifiedAt.Equal(lastModified) {
+			return reallyUpdateProduct(*s, *product)
+		}
+		if attempt == maxProductModifyAttempts {
+			return nil, resp, &ConcurrentModificationError{Name: productName, Attempts: attempt}
+		}
+		time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+	}
+}
+
+// addString returns list with s appended, unless it is already present.
+func addString(list []string, s string) []string {
+	for _, item := range list {
+		if item == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// removeString returns list without any occurrence of s.
+func removeString(list []string, s string) []string {
+	kept := []string{}
+	for _, item := range list {
+		if item != s {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// AddProxy adds an API Proxy to an API Product, if it is not already listed.
+func (s *ProductsServiceOp) AddProxy(productName, proxyName string) (*ApiProduct, *Response, error) {
+	return s.Modify(productName, func(p *ApiProduct) error {
+		p.Proxies = addString(p.Proxies, proxyName)
+		return nil
+	})
+}
+
+// RemoveProxy removes an API Proxy from an API Product.
+func (s *ProductsServiceOp) RemoveProxy(productName, proxyName string) (*ApiProduct, *Response, error) {
+	return s.Modify(productName, func(p *ApiProduct) error {
+		p.Proxies = removeString(p.Proxies, proxyName)
+		return nil
+	})
+}
+
+// AddApiResource adds a resource path, like "/flights/**", to an API Product.
+func (s *ProductsServiceOp) AddApiResource(productName, resource string) (*ApiProduct, *Response, error) {
+	return s.Modify(productName, func(p *ApiProduct) error {
+		p.ApiResources = addString(p.ApiResources, resource)
+		return nil
+	})
+}
+
+// RemoveApiResource removes a resource path from an API Product.
+func (s *ProductsServiceOp) RemoveApiResource(productName, resource string) (*ApiProduct, *Response, error) {
+	return s.Modify(productName, func(p *ApiProduct) error {
+		p.ApiResources = removeString(p.ApiResources, resource)
+		return nil
+	})
+}
+
+// AddEnvironment makes an API Product available in an environment.
+func (s *ProductsServiceOp) AddEnvironment(productName, env string) (*ApiProduct, *Response, error) {
+	return s.Modify(productName, func(p *ApiProduct) error {
+		p.Environments = addString(p.Environments, env)
+		return nil
+	})
+}
+
+// RemoveEnvironment withdraws an API Product from an environment.
+func (s *ProductsServiceOp) RemoveEnvironment(productName, env string) (*ApiProduct, *Response, error) {
+	return s.Modify(productName, func(p *ApiProduct) error {
+		p.Environments = removeString(p.Environments, env)
+		return nil
+	})
+}
+
+// SetQuota sets the quota of an API Product, or removes it if quota is nil.
+func (s *ProductsServiceOp) SetQuota(productName string, quota *ProductQuota) (*ApiProduct, *Response, error) {
+	if quota != nil {
+		if e := quota.Validate(); e != nil {
+			return nil, nil, e
+		}
+	}
+	return s.Modify(productName, func(p *ApiProduct) error {
+		p.SetProductQuota(quota)
+		return nil
+	})
+}
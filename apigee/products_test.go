@@ -2,7 +2,9 @@ package apigee
 
 import (
 	"encoding/json"
+	"errors"
 	"math/rand"
+	"reflect"
 	"testing"
 )
 
@@ -77,3 +79,69 @@ func TestProductCreateDelete(t *testing.T) {
 	}
 	t.Logf("Delete: got=%v", deletedProduct)
 }
+
+func TestProductModify(t *testing.T) {
+	client := NewClientForTesting(t)
+	namelist, _, e := client.Proxies.List()
+	if e != nil {
+		t.Errorf("while listing proxies, error:\n%#v\n", e)
+		return
+	}
+	if len(namelist) < 2 {
+		t.Errorf("need at least two proxies, found %d", len(namelist))
+		return
+	}
+
+	product, e := randomProductFromTemplate(namelist[0])
+	createdProduct, _, e := client.Products.Create(product)
+	if e != nil {
+		t.Errorf("while creating Apigee product, error:\n%#v\n", e)
+		return
+	}
+	defer client.Products.Delete(createdProduct.Name)
+
+	modified, _, e := client.Products.AddProxy(createdProduct.Name, namelist[1])
+	if e != nil {
+		t.Errorf("while adding a proxy, error:\n%#v\n", e)
+		return
+	}
+	if len(modified.Proxies) != 2 || len(modified.Attributes) != len(createdProduct.Attributes) || len(modified.Scopes) != len(createdProduct.Scopes) {
+		t.Errorf("AddProxy: got=%#v", modified)
+	}
+
+	modified, _, e = client.Products.RemoveProxy(createdProduct.Name, namelist[0])
+	if e != nil {
+		t.Errorf("while removing a proxy, error:\n%#v\n", e)
+		return
+	}
+	if len(modified.Proxies) != 1 || modified.Proxies[0] != namelist[1] {
+		t.Errorf("RemoveProxy: got=%v", modified.Proxies)
+	}
+
+	_, _, e = client.Products.Modify(createdProduct.Name, func(p *ApiProduct) error {
+		return errors.New("refused")
+	})
+	if e == nil || e.Error() != "refused" {
+		t.Errorf("Modify: got=%v, expected=refused", e)
+	}
+}
+
+func TestAddRemoveString(t *testing.T) {
+	tt := []struct {
+		desc     string
+		got      []string
+		expected []string
+	}{
+		{"add new", addString([]string{"a"}, "b"), []string{"a", "b"}},
+		{"add existing", addString([]string{"a", "b"}, "a"), []string{"a", "b"}},
+		{"add to nil", addString(nil, "a"), []string{"a"}},
+		{"remove", removeString([]string{"a", "b", "a"}, "a"), []string{"b"}},
+		{"remove missing", removeString([]string{"a"}, "b"), []string{"a"}},
+		{"remove from nil", removeString(nil, "a"), []string{}},
+	}
+	for _, test := range tt {
+		if !reflect.DeepEqual(test.got, test.expected) {
+			t.Errorf("%s: got=%v, expected=%v", test.desc, test.got, test.expected)
+		}
+	}
+}
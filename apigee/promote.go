@@ -0,0 +1,183 @@
+package apigee
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// PromoteOptions holds optional parameters for promoting an API Proxy or
+// SharedFlow revision from one environment to another.
+type PromoteOptions struct {
+	// Optional. The client for the organization to promote into. When nil, or
+	// the same client, the revision is deployed within the source organization.
+	// Otherwise it is exported from the source and imported into the target.
+	Target *ApigeeClient
+
+	// Optional. Options for the import into the target organization, for
+	// example SkipUnchanged to reuse a matching revision there.
+	Import *ImportOptions
+
+	// Optional. The base path at which to deploy an API Proxy. Defaults to the
+	// base path of the deployment in the source environment.
+	BasePath string
+
+	// Optional. Passed to Deploy; see the Admin API documentation for seamless deployment.
+	Override bool
+	Delay    int
+}
+
+// Promotion reports the outcome of a promotion: the revision that was deployed
+// in the source environment, and the revision it became in the target.
+type Promotion struct {
+	Name              string
+	SourceEnvironment string
+	SourceRevision    Revision
+	TargetEnvironment string
+	TargetRevision    Revision
+	// Imported is true when the revision was imported into another organization.
+	Imported bool
+	// Unchanged is true when the target revision was already deployed to the
+	// target environment, so it was not deployed again.
+	Unchanged  bool
+	Deployment *RevisionDeployment
+}
+
+func (p *Promotion) String() string {
+	return fmt.Sprintf("%s: %s revision %d -> %s revision %d",
+		p.Name, p.SourceEnvironment, p.SourceRevision, p.TargetEnvironment, p.TargetRevision)
+}
+
+// deployedIn returns the highest revision that is deployed to env, with its
+// deployment, or nil when no revision is deployed there.
+func deployedIn(deployments *Deployment, env string) *RevisionDeployment {
+	var found *RevisionDeployment
+	for _, ed := range deployments.Environments {
+		if ed.Name != env {
+			continue
+		}
+		for i, rd := range ed.Revision {
+			if rd.State != "deployed" {
+				continue
+			}
+			if found == nil || rd.Number > found.Number {
+				found = &ed.Revision[i]
+			}
+		}
+	}
+	return found
+}
+
+// alreadyDeployed returns the deployment of rev in env when rev is the revision
+// deployed there, in which case promoting it again has nothing to do.
+func alreadyDeployed(deployments *Deployment, env string, rev Revision) *RevisionDeployment {
+	if current := deployedIn(deployments, env); current != nil && current.Number == rev {
+		return current
+	}
+	return nil
+}
+
+// promotionBasePath returns the base path at which to deploy a promoted API
+// Proxy: opts.BasePath if set, else that of the source deployment. Shared flows
+// have no base path.
+func promotionBasePath(uriPathElement string, source *RevisionDeployment, opts *PromoteOptions) string {
+	if uriPathElement != proxiesPath {
+		return ""
+	}
+	if opts.BasePath != "" {
+		return opts.BasePath
+	}
+	if source.Configuration != nil {
+		return source.Configuration.BasePath
+	}
+	return ""
+}
+
+// exportToTempFile exports a revision into a zip in a new temporary directory,
+// which the returned cleanup function removes.
+func exportToTempFile(client *ApigeeClient, uriPathElement, assetName string, rev Revision) (string, func(), error) {
+	req, e := newExportRequest(client, uriPathElement, assetName, rev)
+	if e != nil {
+		return "", nil, e
+	}
+	tempDir, e := ioutil.TempDir("", "go-apigee-")
+	if e != nil {
+		return "", nil, fmt.Errorf("while creating temp dir, error: %#v", e)
+	}
+	cleanup := func() {
+		_ = os.RemoveAll(tempDir)
+	}
+	zipfileName := filepath.Join(tempDir, assetName+".zip")
+	out, e := os.Create(zipfileName)
+	if e != nil {
+		cleanup()
+		return "", nil, e
+	}
+	_, e = client.Do(req, out)
+	out.Close()
+	if e != nil {
+		cleanup()
+		return "", nil, e
+	}
+	return zipfileName, cleanup, nil
+}
+
+// Promote deploys the revision of an API Proxy or SharedFlow that is deployed to
+// sourceEnv, to targetEnv. When opts.Target is another organization, the
+// revision is first exported and imported there. Nothing is deployed when the
+// target revision is already deployed to targetEnv.
+func (s *Deployable) Promote(client *ApigeeClient, uriPathElement, assetName, sourceEnv, targetEnv string, opts *PromoteOptions) (*Promotion, *Response, error) {
+	if opts == nil {
+		opts = &PromoteOptions{}
+	}
+	deployments, resp, e := s.GetDeployments(client, uriPathElement, assetName)
+	if e != nil {
+		return nil, resp, e
+	}
+	source := deployedIn(deployments, sourceEnv)
+	if source == nil {
+		return nil, resp, fmt.Errorf("%s is not deployed to %s", assetName, sourceEnv)
+	}
+	promotion := &Promotion{
+		Name:              assetName,
+		SourceEnvironment: sourceEnv,
+		SourceRevision:    source.Number,
+		TargetEnvironment: targetEnv,
+		TargetRevision:    source.Number,
+	}
+
+	target := client
+	if opts.Target != nil && opts.Target != client {
+		target = opts.Target
+		zipfileName, cleanup, e := exportToTempFile(client, uriPathElement, assetName, source.Number)
+		if e != nil {
+			return nil, nil, fmt.Errorf("while exporting %s revision %d, error: %v", assetName, source.Number, e)
+		}
+		defer cleanup()
+		var imported *DeployableRevision
+		imported, resp, e = s.Import(target, uriPathElement, assetName, zipfileName, opts.Import)
+		if e != nil {
+			return nil, resp, e
+		}
+		promotion.Imported = true
+		promotion.TargetRevision = imported.Revision
+		deployments, resp, e = s.GetDeployments(target, uriPathElement, assetName)
+		if e != nil {
+			return promotion, resp, e
+		}
+	}
+
+	if current := alreadyDeployed(deployments, targetEnv, promotion.TargetRevision); current != nil {
+		promotion.Unchanged = true
+		promotion.Deployment = current
+		return promotion, resp, nil
+	}
+	basepath := promotionBasePath(uriPathElement, source, opts)
+	deployment, resp, e := s.Deploy(target, uriPathElement, assetName, basepath, targetEnv, promotion.TargetRevision, opts.Override, opts.Delay)
+	if e != nil {
+		return promotion, resp, e
+	}
+	promotion.Deployment = deployment
+	return promotion, resp, e
+}
@@ -0,0 +1,83 @@
+package apigee
+
+import "testing"
+
+func TestDeployedIn(t *testing.T) {
+	deployments := &Deployment{Environments: []EnvironmentDeployment{
+		{Name: "test", Revision: []RevisionDeployment{
+			{Number: 4, State: "deployed"},
+			{Number: 6, State: "deployed"},
+			{Number: 7, State: "undeployed"},
+		}},
+		{Name: "prod", Revision: []RevisionDeployment{{Number: 3, State: "error"}}},
+		{Name: "dev", Revision: []RevisionDeployment{{Number: 8, State: "deployed"}}},
+	}}
+
+	testCases := []struct {
+		desc     string
+		env      string
+		expected Revision
+	}{
+		{"highest deployed", "test", 6},
+		{"only one", "dev", 8},
+		{"none deployed", "prod", 0},
+		{"not in environment", "staging", 0},
+	}
+	for _, tc := range testCases {
+		actual := Revision(0)
+		if rd := deployedIn(deployments, tc.env); rd != nil {
+			actual = rd.Number
+		}
+		if actual != tc.expected {
+			t.Errorf("%s: got=%d, expected=%d", tc.desc, actual, tc.expected)
+		}
+	}
+}
+
+func TestAlreadyDeployed(t *testing.T) {
+	deployments := &Deployment{Environments: []EnvironmentDeployment{
+		{Name: "test", Revision: []RevisionDeployment{{Number: 6, State: "deployed"}}},
+		{Name: "prod", Revision: []RevisionDeployment{{Number: 4, State: "deployed"}, {Number: 6, State: "undeployed"}}},
+	}}
+
+	testCases := []struct {
+		desc     string
+		env      string
+		rev      Revision
+		expected bool
+	}{
+		{"same revision deployed", "test", 6, true},
+		{"other revision deployed", "prod", 6, false},
+		{"nothing deployed", "dev", 6, false},
+	}
+	for _, tc := range testCases {
+		actual := alreadyDeployed(deployments, tc.env, tc.rev) != nil
+		if actual != tc.expected {
+			t.Errorf("%s: got=%v, expected=%v", tc.desc, actual, tc.expected)
+		}
+	}
+}
+
+func TestPromotionBasePath(t *testing.T) {
+	configured := &RevisionDeployment{Number: 3, Configuration: &DeploymentConfiguration{BasePath: "/v1"}}
+	unconfigured := &RevisionDeployment{Number: 3}
+
+	testCases := []struct {
+		desc           string
+		uriPathElement string
+		source         *RevisionDeployment
+		opts           *PromoteOptions
+		expected       string
+	}{
+		{"from source", proxiesPath, configured, &PromoteOptions{}, "/v1"},
+		{"option overrides source", proxiesPath, configured, &PromoteOptions{BasePath: "/v2"}, "/v2"},
+		{"no configuration", proxiesPath, unconfigured, &PromoteOptions{}, ""},
+		{"shared flow", sharedFlowPath, configured, &PromoteOptions{BasePath: "/v2"}, ""},
+	}
+	for _, tc := range testCases {
+		actual := promotionBasePath(tc.uriPathElement, tc.source, tc.opts)
+		if actual != tc.expected {
+			t.Errorf("%s: got=%q, expected=%q", tc.desc, actual, tc.expected)
+		}
+	}
+}
@@ -1,20 +1,41 @@
 package apigee
 
+import (
+	"github.com/brayanhenao/go-apigee-edge/bundle"
+)
+
 const proxiesPath = "apis"
 
 // ProxiesService is an interface for interfacing with the Apigee Admin API
 // dealing with apiproxies.
 type ProxiesService interface {
 	Delete(string) (*DeletedItemInfo, *Response, error)
+	DeleteComponent(string, Revision, RevisionComponent, string) (*Response, error)
+	DeleteResourceFile(string, Revision, string, string) (*Response, error)
 	DeleteRevision(string, Revision) (*DeployableRevision, *Response, error)
+	DiffRevisions(string, Revision, Revision) (*bundle.Diff, *Response, error)
+	DiffSource(string, Revision, string) (*bundle.Diff, *Response, error)
 	Deploy(string, string, Revision, bool, int) (*RevisionDeployment, *Response, error)
 	DeployAtPath(string, string, string, Revision, bool, int) (*RevisionDeployment, *Response, error)
 	Export(string, Revision) (string, *Response, error)
+	ExportBundle(string, Revision) (*bundle.Bundle, *Response, error)
 	Get(string) (*DeployableAsset, *Response, error)
+	GetAccess(string) (*ProxyAccess, *Response, error)
+	GetComponent(string, Revision, RevisionComponent, string) ([]byte, *Response, error)
+	GetResourceFile(string, Revision, string, string) ([]byte, *Response, error)
+	GetRevision(string, Revision) (*DeployableRevision, *Response, error)
 	GetDeployments(string) (*Deployment, *Response, error)
 	Import(string, string) (*DeployableRevision, *Response, error)
+	ImportWithOptions(string, string, *ImportOptions) (*DeployableRevision, *Response, error)
 	List() ([]string, *Response, error)
+	ListComponents(string, Revision, RevisionComponent) ([]string, *Response, error)
+	ListResourceFiles(string, Revision) ([]ResourceFile, *Response, error)
+	Promote(string, string, string, *PromoteOptions) (*Promotion, *Response, error)
+	Prune(string, *PruneOptions) (*PruneResult, error)
+	PutComponent(string, Revision, RevisionComponent, string, []byte) (*Response, error)
+	PutResourceFile(string, Revision, string, string, []byte) (*Response, error)
 	Undeploy(string, string, Revision) (*RevisionDeployment, *Response, error)
+	UpdateRevision(string, Revision, string, *ImportOptions) (*DeployableRevision, *Response, error)
 }
 
 type ProxiesServiceOp struct {
@@ -56,7 +77,13 @@ func (s *ProxiesServiceOp) Get(proxyName string) (*DeployableAsset, *Response, e
 // the path of a zip file containing an API Proxy bundle. Returns the API proxy revision information.
 // This method does not deploy the imported proxy. See the Deploy method.
 func (s *ProxiesServiceOp) Import(proxyName string, source string) (*DeployableRevision, *Response, error) {
-	return s.deployable.Import(s.client, proxiesPath, proxyName, source)
+	return s.deployable.Import(s.client, proxiesPath, proxyName, source, nil)
+}
+
+// ImportWithOptions imports an API proxy like Import. When the source is a
+// directory, the options control which files are included in the bundle.
+func (s *ProxiesServiceOp) ImportWithOptions(proxyName string, source string, opts *ImportOptions) (*DeployableRevision, *Response, error) {
+	return s.deployable.Import(s.client, proxiesPath, proxyName, source, opts)
 }
 
 // Export a revision of an API proxy within an organization, to a filesystem file.
@@ -97,3 +124,98 @@ func (s *ProxiesServiceOp) Delete(proxyName string) (*DeletedItemInfo, *Response
 func (s *ProxiesServiceOp) GetDeployments(proxyName string) (*Deployment, *Response, error) {
 	return s.deployable.GetDeployments(s.client, proxiesPath, proxyName)
 }
+
+// ExportBundle retrieves a revision of an API proxy and parses it, without
+// writing it to the filesystem.
+func (s *ProxiesServiceOp) ExportBundle(proxyName string, rev Revision) (*bundle.Bundle, *Response, error) {
+	return s.deployable.ExportBundle(s.client, proxiesPath, proxyName, rev)
+}
+
+// DiffRevisions compares two revisions of an API proxy, and reports the
+// changed files as well as semantic changes like added or removed policies,
+// changed flow conditions and changed target URLs.
+func (s *ProxiesServiceOp) DiffRevisions(proxyName string, from, to Revision) (*bundle.Diff, *Response, error) {
+	return s.deployable.DiffRevisions(s.client, proxiesPath, proxyName, from, to)
+}
+
+// DiffSource compares a revision of an API proxy with a local bundle, which can
+// be a directory containing an exploded apiproxy bundle or a zip file.
+func (s *ProxiesServiceOp) DiffSource(proxyName string, rev Revision, source string) (*bundle.Diff, *Response, error) {
+	return s.deployable.DiffSource(s.client, proxiesPath, proxyName, rev, source)
+}
+
+// UpdateRevision overwrites the contents of an existing revision of an API proxy
+// with the bundle at source, rather than creating a new revision. The source can
+// be a directory containing an exploded apiproxy bundle, or a zip file, as for
+// Import. This is useful during development, when the revision is not deployed.
+// The options apply as for ImportWithOptions; opts may be nil.
+func (s *ProxiesServiceOp) UpdateRevision(proxyName string, rev Revision, source string, opts *ImportOptions) (*DeployableRevision, *Response, error) {
+	return s.deployable.UpdateRevision(s.client, proxiesPath, proxyName, rev, source, opts)
+}
+
+// GetRevision retrieves the information about a single revision of an API proxy, including
+// the names of the policies, endpoints and resources it contains.
+func (s *ProxiesServiceOp) GetRevision(proxyName string, rev Revision) (*DeployableRevision, *Response, error) {
+	return s.deployable.GetRevision(s.client, proxiesPath, proxyName, rev)
+}
+
+// ListComponents retrieves the names of the policies, proxy endpoints or target endpoints
+// within a revision of an API proxy.
+func (s *ProxiesServiceOp) ListComponents(proxyName string, rev Revision, component RevisionComponent) ([]string, *Response, error) {
+	return s.deployable.ListRevisionComponents(s.client, proxiesPath, proxyName, rev, component)
+}
+
+// GetComponent retrieves the XML of a policy, proxy endpoint or target endpoint within a
+// revision of an API proxy, without exporting the whole bundle.
+func (s *ProxiesServiceOp) GetComponent(proxyName string, rev Revision, component RevisionComponent, name string) ([]byte, *Response, error) {
+	return s.deployable.GetRevisionComponent(s.client, proxiesPath, proxyName, rev, component, name)
+}
+
+// PutComponent writes the XML of a policy, proxy endpoint or target endpoint within a
+// revision of an API proxy, creating it if it does not exist.
+func (s *ProxiesServiceOp) PutComponent(proxyName string, rev Revision, component RevisionComponent, name string, content []byte) (*Response, error) {
+	return s.deployable.PutRevisionComponent(s.client, proxiesPath, proxyName, rev, component, name, content)
+}
+
+// DeleteComponent removes a policy, proxy endpoint or target endpoint from a revision of
+// an API proxy.
+func (s *ProxiesServiceOp) DeleteComponent(proxyName string, rev Revision, component RevisionComponent, name string) (*Response, error) {
+	return s.deployable.DeleteRevisionComponent(s.client, proxiesPath, proxyName, rev, component, name)
+}
+
+// ListResourceFiles retrieves the names and types of the resource files within a revision
+// of an API proxy.
+func (s *ProxiesServiceOp) ListResourceFiles(proxyName string, rev Revision) ([]ResourceFile, *Response, error) {
+	return s.deployable.ListResourceFiles(s.client, proxiesPath, proxyName, rev)
+}
+
+// GetResourceFile retrieves the content of a resource file, like a JavaScript file of type
+// "jsc", within a revision of an API proxy.
+func (s *ProxiesServiceOp) GetResourceFile(proxyName string, rev Revision, resourceType string, name string) ([]byte, *Response, error) {
+	return s.deployable.GetResourceFile(s.client, proxiesPath, proxyName, rev, resourceType, name)
+}
+
+// PutResourceFile writes a resource file within a revision of an API proxy, replacing the
+// file if it exists and creating it otherwise.
+func (s *ProxiesServiceOp) PutResourceFile(proxyName string, rev Revision, resourceType string, name string, content []byte) (*Response, error) {
+	return s.deployable.PutResourceFile(s.client, proxiesPath, proxyName, rev, resourceType, name, content)
+}
+
+// DeleteResourceFile removes a resource file from a revision of an API proxy.
+func (s *ProxiesServiceOp) DeleteResourceFile(proxyName string, rev Revision, resourceType string, name string) (*Response, error) {
+	return s.deployable.DeleteResourceFile(s.client, proxiesPath, proxyName, rev, resourceType, name)
+}
+
+// Prune deletes old revisions of an API proxy according to the retention policy in opts.
+// Revisions that are deployed, and the most recent revision, are always kept. Use
+// opts.DryRun to see what would be deleted.
+func (s *ProxiesServiceOp) Prune(proxyName string, opts *PruneOptions) (*PruneResult, error) {
+	return s.deployable.Prune(s.client, proxiesPath, proxyName, opts)
+}
+
+// Promote deploys the revision of an API proxy that is currently deployed to sourceEnv, to
+// targetEnv. When opts.Target is a client for another organization, the revision is exported
+// and imported there first. The returned Promotion maps the source revision to the target revision.
+func (s *ProxiesServiceOp) Promote(proxyName, sourceEnv, targetEnv string, opts *PromoteOptions) (*Promotion, *Response, error) {
+	return s.deployable.Promote(s.client, proxiesPath, proxyName, sourceEnv, targetEnv, opts)
+}
@@ -9,6 +9,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/brayanhenao/go-apigee-edge/bundle"
 )
 
 const (
@@ -235,7 +237,7 @@ func TestProxyDeployUndeploy(t *testing.T) {
 		randomPath := fmt.Sprintf("/%s-deploy", timestamp)
 		env := environmentList[rand.Intn(len(environmentList))]
 		importedProxies = append(importedProxies, ImportedProxyStruct{proxyName: proxyName, env: env, rev: proxyRev.Revision})
-		deployment, resp, e := client.Proxies.DeployAtPath(proxyName, randomPath, env, proxyRev.Revision)
+		deployment, resp, e := client.Proxies.DeployAtPath(proxyName, randomPath, env, proxyRev.Revision, false, 0)
 		if e != nil {
 			t.Errorf("while deploying, error:\n%#v\n", e)
 			return
@@ -308,7 +310,7 @@ func TestProxyInquireDeployment(t *testing.T) {
 
 	// deploy
 	randomPath := fmt.Sprintf("/%s-inquiredeployment", timestamp)
-	revisionDeployment, resp, e := client.Proxies.DeployAtPath(proxyName, randomPath, env, proxyRev.Revision)
+	revisionDeployment, resp, e := client.Proxies.DeployAtPath(proxyName, randomPath, env, proxyRev.Revision, false, 0)
 	if e != nil {
 		t.Errorf("while deploying, error:\n%#v\n", e)
 		return
@@ -452,7 +454,7 @@ func TestProxyDeleteFail(t *testing.T) {
 	defer resp.Body.Close()
 
 	randomPath := fmt.Sprintf("/%s-deletefail", timestamp)
-	deployment, resp, e := client.Proxies.DeployAtPath(proxyName, randomPath, env, proxyRev.Revision)
+	deployment, resp, e := client.Proxies.DeployAtPath(proxyName, randomPath, env, proxyRev.Revision, false, 0)
 	if e != nil {
 		t.Errorf("while deploying, error:\n%#v\n", e)
 		return
@@ -504,3 +506,138 @@ func TestProxyDeleteFail(t *testing.T) {
 	t.Logf("deleted %#v", deletedItem)
 
 }
+
+func TestProxyRevisionComponents(t *testing.T) {
+	now := time.Now()
+	timestamp := fmt.Sprintf("%d%02d%02d-%02d%02d%02d",
+		now.Year(), now.Month(), now.Day(),
+		now.Hour(), now.Minute(), now.Second())
+
+	client := NewClientForTesting(t)
+	fullDirName := path.Join(proxyBundleDir, "apiproxy-resourcetest1")
+	proxyName := fmt.Sprintf("%s-components-%s", testPrefix, timestamp)
+	proxyRev, resp, e := client.Proxies.Import(proxyName, fullDirName)
+	if e != nil {
+		t.Errorf("while importing, error:\n%#v\n", e)
+		return
+	}
+	defer resp.Body.Close()
+
+	revision, resp, e := client.Proxies.GetRevision(proxyName, proxyRev.Revision)
+	if e != nil {
+		t.Errorf("while getting revision, error:\n%#v\n", e)
+		return
+	}
+	if len(revision.Policies) != 3 {
+		t.Errorf("policies in revision: got=%v", revision.Policies)
+	}
+
+	policies, resp, e := client.Proxies.ListComponents(proxyName, proxyRev.Revision, PolicyComponent)
+	if e != nil {
+		t.Errorf("while listing policies, error:\n%#v\n", e)
+		return
+	}
+	if len(policies) != 3 {
+		t.Errorf("listed policies: got=%v", policies)
+	}
+
+	content, resp, e := client.Proxies.GetComponent(proxyName, proxyRev.Revision, PolicyComponent, "AM-BasicResponse")
+	if e != nil {
+		t.Errorf("while getting policy, error:\n%#v\n", e)
+		return
+	}
+	if !strings.Contains(string(content), "<AssignMessage") {
+		t.Errorf("policy content: got=%s", content)
+	}
+	resp, e = client.Proxies.PutComponent(proxyName, proxyRev.Revision, PolicyComponent, "AM-BasicResponse", content)
+	if e != nil {
+		t.Errorf("while putting policy, error:\n%#v\n", e)
+		return
+	}
+	added := []byte(`<AssignMessage name="AM-Added"><AssignVariable><Name>added</Name><Value>true</Value></AssignVariable></AssignMessage>`)
+	resp, e = client.Proxies.PutComponent(proxyName, proxyRev.Revision, PolicyComponent, "AM-Added", added)
+	if e != nil {
+		t.Errorf("while creating policy, error:\n%#v\n", e)
+		return
+	}
+	policies, resp, e = client.Proxies.ListComponents(proxyName, proxyRev.Revision, PolicyComponent)
+	if e != nil {
+		t.Errorf("while listing policies, error:\n%#v\n", e)
+		return
+	}
+	if len(policies) != 4 {
+		t.Errorf("listed policies after create: got=%v", policies)
+	}
+
+	resources, resp, e := client.Proxies.ListResourceFiles(proxyName, proxyRev.Revision)
+	if e != nil {
+		t.Errorf("while listing resource files, error:\n%#v\n", e)
+		return
+	}
+	if len(resources) != 1 || resources[0].URL() != "jsc://insertResponseHeader.js" {
+		t.Errorf("resource files: got=%#v", resources)
+	}
+	script := []byte("context.setVariable('response.header.x-test', 'true');\n")
+	resp, e = client.Proxies.PutResourceFile(proxyName, proxyRev.Revision, "jsc", "added.js", script)
+	if e != nil {
+		t.Errorf("while creating resource file, error:\n%#v\n", e)
+		return
+	}
+	content, resp, e = client.Proxies.GetResourceFile(proxyName, proxyRev.Revision, "jsc", "added.js")
+	if e != nil {
+		t.Errorf("while getting resource file, error:\n%#v\n", e)
+		return
+	}
+	if string(content) != string(script) {
+		t.Errorf("resource file content: got=%q, expected=%q", content, script)
+	}
+	resp, e = client.Proxies.DeleteResourceFile(proxyName, proxyRev.Revision, "jsc", "added.js")
+	if e != nil {
+		t.Errorf("while deleting resource file, error:\n%#v\n", e)
+	}
+
+	_, _, e = client.Proxies.Delete(proxyName)
+	if e != nil {
+		t.Errorf("while deleting proxy, error:\n%#v\n", e)
+	}
+}
+
+func TestProxyUpdateRevision(t *testing.T) {
+	now := time.Now()
+	timestamp := fmt.Sprintf("%d%02d%02d-%02d%02d%02d",
+		now.Year(), now.Month(), now.Day(),
+		now.Hour(), now.Minute(), now.Second())
+
+	client := NewClientForTesting(t)
+	proxyName := fmt.Sprintf("%s-update-%s", testPrefix, timestamp)
+	proxyRev, resp, e := client.Proxies.Import(proxyName, path.Join(proxyBundleDir, "apiproxy-resourcetest1"))
+	if e != nil {
+		t.Errorf("while importing, error:\n%#v\n", e)
+		return
+	}
+	defer resp.Body.Close()
+	defer client.Proxies.Delete(proxyName)
+
+	opts := &ImportOptions{RecordFingerprint: true}
+	updatedRev, resp, e := client.Proxies.UpdateRevision(proxyName, proxyRev.Revision, path.Join(proxyBundleDir, "apiproxy-resourcetest1"), opts)
+	if e != nil {
+		t.Errorf("while updating, error:\n%#v\n", e)
+		return
+	}
+	if updatedRev.Revision != proxyRev.Revision {
+		t.Errorf("revision: got=%d, expected=%d", updatedRev.Revision, proxyRev.Revision)
+	}
+	if bundle.RecordedFingerprint(updatedRev.Description) == "" {
+		t.Errorf("no fingerprint recorded in description %q", updatedRev.Description)
+	}
+
+	opts = &ImportOptions{SkipUnchanged: true}
+	unchangedRev, resp, e := client.Proxies.UpdateRevision(proxyName, proxyRev.Revision, path.Join(proxyBundleDir, "apiproxy-resourcetest1"), opts)
+	if e != nil {
+		t.Errorf("while updating again, error:\n%#v\n", e)
+		return
+	}
+	if unchangedRev.Revision != proxyRev.Revision || unchangedRev.Description != updatedRev.Description {
+		t.Errorf("unchanged revision: got=%#v", unchangedRev)
+	}
+}
@@ -0,0 +1,262 @@
+package apigee
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+const defaultParallelism = 4
+
+// PruneOptions holds the retention policy for pruning the revisions of an API
+// Proxy or SharedFlow. Revisions that are currently deployed, and the most
+// recent revision, are always kept. A revision that is kept by any rule is not
+// deleted.
+type PruneOptions struct {
+	// Optional. The number of most recent revisions to keep, whether deployed or not.
+	KeepLast int
+
+	// Optional. Keep revisions created within this duration of the present.
+	// Applying this rule requires retrieving each candidate revision.
+	KeepNewerThan time.Duration
+
+	// Optional. Keep revisions deployed within this duration of the present.
+	// The Admin API keeps no deployment history, so this is a best effort: a
+	// revision is kept if Edge still lists it as undeployed from an
+	// environment, which happens shortly after an undeploy, or if it was
+	// created or last modified within the duration. Like KeepNewerThan, this
+	// requires retrieving each candidate revision.
+	KeepDeployedWithin time.Duration
+
+	// Optional. When true, report what would be deleted without deleting anything.
+	DryRun bool
+
+	// Optional. The number of revisions to delete at once. Defaults to 4.
+	Parallelism int
+
+	// Optional. When set, a line describing the fate of each revision is written here.
+	Output io.Writer
+}
+
+// PruneResult reports the outcome of a prune. On a dry run, Deleted lists the
+// revisions that would have been deleted. Failed holds the error for each
+// revision that could not be deleted.
+type PruneResult struct {
+	Kept    []Revision
+	Deleted []Revision
+	Failed  map[Revision]error
+}
+
+// pruneCandidate is a revision together with the reason it is kept, if any.
+type pruneCandidate struct {
+	rev    Revision
+	reason string
+}
+
+// envDeployment is the state of a revision's deployment to one environment.
+type envDeployment struct {
+	env   string
+	state string
+}
+
+// keepReason applies the rules that need no further information from the
+// Admin API. Revisions are ranked from the most recent, starting at 0. A
+// revision is kept while any deployment of it is deployed, or in a state like
+// pending or error in which Edge still holds it in the environment. An
+// undeployed revision that Edge still lists is kept only for
+// KeepDeployedWithin.
+func keepReason(rev Revision, rank int, deployments map[Revision][]envDeployment, opts *PruneOptions) string {
+	for _, d := range deployments[rev] {
+		switch d.state {
+		case "deployed":
+			return "deployed to " + d.env
+		case "undeployed":
+			if opts.KeepDeployedWithin > 0 {
+				return "recently undeployed from " + d.env
+			}
+		default:
+			return fmt.Sprintf("deployment to %s is %s", d.env, d.state)
+		}
+	}
+	if rank == 0 {
+		return "most recent revision"
+	}
+	if rank < opts.KeepLast {
+		return fmt.Sprintf("one of the %d most recent revisions", opts.KeepLast)
+	}
+	return ""
+}
+
+// recentReason applies the rules that need the details of the revision.
+func recentReason(detail *DeployableRevision, opts *PruneOptions, now time.Time) string {
+	created := detail.CreatedAt.Time
+	if opts.KeepNewerThan > 0 && now.Sub(created) < opts.KeepNewerThan {
+		return "created at " + created.Format(time.RFC3339)
+	}
+	if opts.KeepDeployedWithin > 0 {
+		changed := detail.LastModifiedAt.Time
+		if created.After(changed) {
+			changed = created
+		}
+		if now.Sub(changed) < opts.KeepDeployedWithin {
+			return "changed at " + changed.Format(time.RFC3339) + ", so possibly deployed since"
+		}
+	}
+	return ""
+}
+
+// forEachRevision calls fn for each revision, running up to parallelism calls
+// at once, and returns the errors by revision.
+func forEachRevision(revs []Revision, parallelism int, fn func(Revision) error) map[Revision]error {
+	failed := map[Revision]error{}
+	for i, e := range runParallel(len(revs), parallelism, func(i int) error { return fn(revs[i]) }) {
+		failed[revs[i]] = e
+	}
+	return failed
+}
+
+// runParallel calls fn for each index from 0 to n-1, running up to parallelism
+// calls at once, and returns the errors by index.
+func runParallel(n, parallelism int, fn func(int) error) map[int]error {
+	if parallelism <= 0 {
+		parallelism = defaultParallelism
+	}
+	failed := map[int]error{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	work := make(chan int)
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				if e := fn(i); e != nil {
+					mu.Lock()
+					failed[i] = e
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	return failed
+}
+
+// firstError returns the error for the lowest revision in failed.
+func firstError(failed map[Revision]error) error {
+	var first Revision = -1
+	for rev := range failed {
+		if first < 0 || rev < first {
+			first = rev
+		}
+	}
+	return failed[first]
+}
+
+// Prune deletes the revisions of an API Proxy or SharedFlow that are not
+// retained by the policy in opts.
+func (s *Deployable) Prune(client *ApigeeClient, uriPathElement, assetName string, opts *PruneOptions) (*PruneResult, error) {
+	if opts == nil {
+		opts = &PruneOptions{}
+	}
+	asset, _, e := s.Get(client, uriPathElement, assetName)
+	if e != nil {
+		return nil, e
+	}
+	deployment, _, e := s.GetDeployments(client, uriPathElement, assetName)
+	if e != nil {
+		return nil, e
+	}
+	deployments := map[Revision][]envDeployment{}
+	for _, env := range deployment.Environments {
+		for _, rd := range env.Revision {
+			deployments[rd.Number] = append(deployments[rd.Number], envDeployment{env: env.Name, state: rd.State})
+		}
+	}
+
+	revisions := append([]Revision{}, asset.Revisions...)
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i] > revisions[j] })
+	now := time.Now()
+	candidates := make([]pruneCandidate, len(revisions))
+	undecided := []Revision{}
+	for i, rev := range revisions {
+		candidates[i] = pruneCandidate{rev: rev, reason: keepReason(rev, i, deployments, opts)}
+		if candidates[i].reason == "" {
+			undecided = append(undecided, rev)
+		}
+	}
+
+	if (opts.KeepNewerThan > 0 || opts.KeepDeployedWithin > 0) && len(undecided) > 0 {
+		var mu sync.Mutex
+		details := map[Revision]*DeployableRevision{}
+		failed := forEachRevision(undecided, opts.Parallelism, func(rev Revision) error {
+			detail, _, e := s.GetRevision(client, uriPathElement, assetName, rev)
+			if e != nil {
+				return e
+			}
+			mu.Lock()
+			details[rev] = detail
+			mu.Unlock()
+			return nil
+		})
+		if len(failed) > 0 {
+			return nil, fmt.Errorf("while getting %d revisions, error: %v", len(failed), firstError(failed))
+		}
+		for i := range candidates {
+			if detail, ok := details[candidates[i].rev]; ok {
+				candidates[i].reason = recentReason(detail, opts, now)
+			}
+		}
+	}
+
+	result := &PruneResult{Failed: map[Revision]error{}}
+	doomed := []Revision{}
+	for _, c := range candidates {
+		if c.reason != "" {
+			result.Kept = append(result.Kept, c.rev)
+			if opts.Output != nil {
+				fmt.Fprintf(opts.Output, "keep %s revision %d: %s\n", assetName, c.rev, c.reason)
+			}
+			continue
+		}
+		doomed = append(doomed, c.rev)
+	}
+
+	if opts.DryRun {
+		result.Deleted = doomed
+		if opts.Output != nil {
+			for _, rev := range doomed {
+				fmt.Fprintf(opts.Output, "would delete %s revision %d\n", assetName, rev)
+			}
+		}
+		return result, nil
+	}
+
+	result.Failed = forEachRevision(doomed, opts.Parallelism, func(rev Revision) error {
+		_, _, e := s.DeleteRevision(client, uriPathElement, assetName, rev)
+		return e
+	})
+	for _, rev := range doomed {
+		e, failed := result.Failed[rev]
+		if !failed {
+			result.Deleted = append(result.Deleted, rev)
+		}
+		if opts.Output != nil {
+			if failed {
+				fmt.Fprintf(opts.Output, "failed to delete %s revision %d: %v\n", assetName, rev, e)
+			} else {
+				fmt.Fprintf(opts.Output, "deleted %s revision %d\n", assetName, rev)
+			}
+		}
+	}
+	if len(result.Failed) > 0 {
+		return result, fmt.Errorf("failed to delete %d of %d revisions", len(result.Failed), len(doomed))
+	}
+	return result, nil
+}
@@ -0,0 +1,85 @@
+package apigee
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKeepReason(t *testing.T) {
+	deployments := map[Revision][]envDeployment{
+		7: {{env: "test", state: "deployed"}},
+		6: {{env: "test", state: "undeployed"}, {env: "prod", state: "error"}},
+		5: {{env: "prod", state: "pending"}},
+		4: {{env: "test", state: "undeployed"}},
+	}
+	keepLast := &PruneOptions{KeepLast: 3}
+	keepDeployed := &PruneOptions{KeepDeployedWithin: 30 * 24 * time.Hour}
+
+	testCases := []struct {
+		desc     string
+		rev      Revision
+		rank     int
+		opts     *PruneOptions
+		expected string
+	}{
+		{"deployed", 7, 3, keepLast, "deployed to test"},
+		{"failed deployment", 6, 4, keepLast, "deployment to prod is error"},
+		{"pending deployment", 5, 5, keepLast, "deployment to prod is pending"},
+		{"undeployed", 4, 6, keepLast, ""},
+		{"recently undeployed", 4, 6, keepDeployed, "recently undeployed from test"},
+		{"most recent", 10, 0, keepLast, "most recent revision"},
+		{"within KeepLast", 9, 2, keepLast, "one of the 3 most recent revisions"},
+		{"beyond KeepLast", 3, 7, keepLast, ""},
+		{"never deployed", 1, 9, keepDeployed, ""},
+	}
+	for _, tc := range testCases {
+		actual := keepReason(tc.rev, tc.rank, deployments, tc.opts)
+		if actual != tc.expected {
+			t.Errorf("%s: got=%q, expected=%q", tc.desc, actual, tc.expected)
+		}
+	}
+}
+
+func TestRecentReason(t *testing.T) {
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) Timestamp { return Timestamp{now.Add(-time.Duration(days) * 24 * time.Hour)} }
+	newer := &PruneOptions{KeepNewerThan: 7 * 24 * time.Hour}
+	deployed := &PruneOptions{KeepDeployedWithin: 30 * 24 * time.Hour}
+
+	testCases := []struct {
+		desc     string
+		detail   DeployableRevision
+		opts     *PruneOptions
+		expected string
+	}{
+		{"created recently", DeployableRevision{CreatedAt: daysAgo(2)}, newer, "created at 2020-05-30T00:00:00Z"},
+		{"created long ago", DeployableRevision{CreatedAt: daysAgo(20), LastModifiedAt: daysAgo(2)}, newer, ""},
+		{"modified recently", DeployableRevision{CreatedAt: daysAgo(90), LastModifiedAt: daysAgo(10)}, deployed,
+			"changed at 2020-05-22T00:00:00Z, so possibly deployed since"},
+		{"unchanged for long", DeployableRevision{CreatedAt: daysAgo(90), LastModifiedAt: daysAgo(60)}, deployed, ""},
+		{"no rules", DeployableRevision{CreatedAt: daysAgo(1)}, &PruneOptions{}, ""},
+	}
+	for _, tc := range testCases {
+		actual := recentReason(&tc.detail, tc.opts, now)
+		if actual != tc.expected {
+			t.Errorf("%s: got=%q, expected=%q", tc.desc, actual, tc.expected)
+		}
+	}
+}
+
+func TestForEachRevision(t *testing.T) {
+	revs := []Revision{1, 2, 3, 4, 5, 6}
+	failed := forEachRevision(revs, 3, func(rev Revision) error {
+		if rev%2 == 0 {
+			return errors.New("even")
+		}
+		return nil
+	})
+	if len(failed) != 3 || failed[2] == nil || failed[1] != nil {
+		t.Errorf("failed: got=%v", failed)
+	}
+	if e := firstError(failed); e == nil || failed[2] != e {
+		t.Errorf("first error: got=%v", e)
+	}
+}
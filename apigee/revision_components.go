@@ -0,0 +1,205 @@
+package apigee
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+)
+
+const appXml = "application/xml"
+
+// RevisionComponent identifies a kind of XML component within a revision of an
+// API Proxy or SharedFlow. The value is the path element used by the Admin API.
+type RevisionComponent string
+
+const (
+	// Policies are present in both API Proxies and SharedFlows.
+	PolicyComponent RevisionComponent = "policies"
+	// ProxyEndpoints and TargetEndpoints are present only in API Proxies.
+	ProxyEndpointComponent  RevisionComponent = "proxies"
+	TargetEndpointComponent RevisionComponent = "targets"
+	// Shared flows are present only in SharedFlows.
+	SharedFlowComponent RevisionComponent = "sharedflows"
+)
+
+// ResourceFile identifies a resource file, like a JavaScript or XSL file, within
+// a revision of an API Proxy or SharedFlow. Type is one of "jsc", "xsl", "java",
+// "node", "py", "wsdl" or "xsd".
+type ResourceFile struct {
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
+}
+
+// URL returns the URL by which policies refer to the resource, eg "jsc://hello.js".
+func (r ResourceFile) URL() string {
+	return r.Type + "://" + r.Name
+}
+
+type resourceFileList struct {
+	ResourceFiles []ResourceFile `json:"resourceFile"`
+}
+
+func revisionPath(uriPathElement, assetName string, rev Revision, elements ...string) string {
+	return path.Join(append([]string{uriPathElement, assetName, "revisions", fmt.Sprintf("%d", rev)}, elements...)...)
+}
+
+// newContentRequest creates a request that sends and receives the raw content
+// of a file, rather than JSON.
+func newContentRequest(client *ApigeeClient, method, path string, content []byte, ctype, accept string) (*http.Request, error) {
+	var body interface{}
+	if content != nil {
+		body = bytes.NewReader(content)
+	}
+	req, e := client.NewRequest(method, path, body)
+	if e != nil {
+		return nil, e
+	}
+	if content != nil {
+		req.Header.Set("Content-Type", ctype)
+	}
+	req.Header.Set("Accept", accept)
+	return req, nil
+}
+
+// ListRevisionComponents retrieves the names of the policies, endpoints or
+// shared flows within a revision of an API Proxy or SharedFlow.
+func (s *Deployable) ListRevisionComponents(client *ApigeeClient, uriPathElement, assetName string, rev Revision, component RevisionComponent) ([]string, *Response, error) {
+	path := revisionPath(uriPathElement, assetName, rev, string(component))
+	req, e := client.NewRequest("GET", path, nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	namelist := make([]string, 0)
+	resp, e := client.Do(req, &namelist)
+	if e != nil {
+		return nil, resp, e
+	}
+	return namelist, resp, e
+}
+
+// GetRevisionComponent retrieves the XML of a single policy, endpoint or
+// shared flow within a revision. The result can be parsed with the functions in
+// the bundle package, like bundle.ParsePolicy.
+func (s *Deployable) GetRevisionComponent(client *ApigeeClient, uriPathElement, assetName string, rev Revision, component RevisionComponent, name string) ([]byte, *Response, error) {
+	path := revisionPath(uriPathElement, assetName, rev, string(component), name)
+	req, e := newContentRequest(client, "GET", path, nil, "", appXml)
+	if e != nil {
+		return nil, nil, e
+	}
+	buf := new(bytes.Buffer)
+	resp, e := client.Do(req, buf)
+	if e != nil {
+		return nil, resp, e
+	}
+	return buf.Bytes(), resp, e
+}
+
+// PutRevisionComponent writes the XML of a policy, endpoint or shared flow
+// within a revision. An existing component is replaced; otherwise the
+// component is created.
+func (s *Deployable) PutRevisionComponent(client *ApigeeClient, uriPathElement, assetName string, rev Revision, component RevisionComponent, name string, content []byte) (*Response, error) {
+	path := revisionPath(uriPathElement, assetName, rev, string(component), name)
+	req, e := newContentRequest(client, "PUT", path, content, appXml, appJson)
+	if e != nil {
+		return nil, e
+	}
+	resp, e := client.Do(req, nil)
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		return resp, e
+	}
+
+	// The component does not exist yet, so create it.
+	origURL, e := url.Parse(revisionPath(uriPathElement, assetName, rev, string(component)))
+	if e != nil {
+		return nil, e
+	}
+	q := origURL.Query()
+	q.Add("name", name)
+	origURL.RawQuery = q.Encode()
+	req, e = newContentRequest(client, "POST", origURL.String(), content, appXml, appJson)
+	if e != nil {
+		return nil, e
+	}
+	return client.Do(req, nil)
+}
+
+// DeleteRevisionComponent removes a policy, endpoint or shared flow from a revision.
+func (s *Deployable) DeleteRevisionComponent(client *ApigeeClient, uriPathElement, assetName string, rev Revision, component RevisionComponent, name string) (*Response, error) {
+	path := revisionPath(uriPathElement, assetName, rev, string(component), name)
+	req, e := client.NewRequest("DELETE", path, nil)
+	if e != nil {
+		return nil, e
+	}
+	return client.Do(req, nil)
+}
+
+// ListResourceFiles retrieves the resource files within a revision.
+func (s *Deployable) ListResourceFiles(client *ApigeeClient, uriPathElement, assetName string, rev Revision) ([]ResourceFile, *Response, error) {
+	path := revisionPath(uriPathElement, assetName, rev, "resourcefiles")
+	req, e := client.NewRequest("GET", path, nil)
+	if e != nil {
+		return nil, nil, e
+	}
+	list := resourceFileList{}
+	resp, e := client.Do(req, &list)
+	if e != nil {
+		return nil, resp, e
+	}
+	return list.ResourceFiles, resp, e
+}
+
+// GetResourceFile retrieves the content of a resource file within a revision.
+func (s *Deployable) GetResourceFile(client *ApigeeClient, uriPathElement, assetName string, rev Revision, resourceType, name string) ([]byte, *Response, error) {
+	path := revisionPath(uriPathElement, assetName, rev, "resourcefiles", resourceType, name)
+	req, e := newContentRequest(client, "GET", path, nil, "", octetStream)
+	if e != nil {
+		return nil, nil, e
+	}
+	buf := new(bytes.Buffer)
+	resp, e := client.Do(req, buf)
+	if e != nil {
+		return nil, resp, e
+	}
+	return buf.Bytes(), resp, e
+}
+
+// PutResourceFile writes the content of a resource file within a revision. An
+// existing file is replaced; otherwise the file is created.
+func (s *Deployable) PutResourceFile(client *ApigeeClient, uriPathElement, assetName string, rev Revision, resourceType, name string, content []byte) (*Response, error) {
+	path := revisionPath(uriPathElement, assetName, rev, "resourcefiles", resourceType, name)
+	req, e := newContentRequest(client, "PUT", path, content, octetStream, appJson)
+	if e != nil {
+		return nil, e
+	}
+	resp, e := client.Do(req, nil)
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		return resp, e
+	}
+
+	// The file does not exist yet, so create it.
+	origURL, e := url.Parse(revisionPath(uriPathElement, assetName, rev, "resourcefiles"))
+	if e != nil {
+		return nil, e
+	}
+	q := origURL.Query()
+	q.Add("type", resourceType)
+	q.Add("name", name)
+	origURL.RawQuery = q.Encode()
+	req, e = newContentRequest(client, "POST", origURL.String(), content, octetStream, appJson)
+	if e != nil {
+		return nil, e
+	}
+	return client.Do(req, nil)
+}
+
+// DeleteResourceFile removes a resource file from a revision.
+func (s *Deployable) DeleteResourceFile(client *ApigeeClient, uriPathElement, assetName string, rev Revision, resourceType, name string) (*Response, error) {
+	path := revisionPath(uriPathElement, assetName, rev, "resourcefiles", resourceType, name)
+	req, e := client.NewRequest("DELETE", path, nil)
+	if e != nil {
+		return nil, e
+	}
+	return client.Do(req, nil)
+}
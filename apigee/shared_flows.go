@@ -1,19 +1,44 @@
 package apigee
 
+import (
+	"fmt"
+
+	"github.com/brayanhenao/go-apigee-edge/bundle"
+)
+
 const sharedFlowPath = "sharedflows"
 
 // SharedFlowsService is an interface for interfacing with the Apigee Admin API
 // dealing with apiproxies.
 type SharedFlowsService interface {
+	CheckDependents(string, ...string) error
 	Delete(string) (*DeletedItemInfo, *Response, error)
+	DeleteComponent(string, Revision, RevisionComponent, string) (*Response, error)
+	DeleteResourceFile(string, Revision, string, string) (*Response, error)
 	DeleteRevision(string, Revision) (*DeployableRevision, *Response, error)
+	DiffRevisions(string, Revision, Revision) (*bundle.Diff, *Response, error)
+	DiffSource(string, Revision, string) (*bundle.Diff, *Response, error)
 	Deploy(string, string, Revision, bool, int) (*RevisionDeployment, *Response, error)
 	Export(string, Revision) (string, *Response, error)
+	ExportBundle(string, Revision) (*bundle.Bundle, *Response, error)
+	ForceDelete(string) (*DeletedItemInfo, *Response, error)
+	ForceUndeploy(string, string, Revision) (*RevisionDeployment, *Response, error)
 	Get(string) (*DeployableAsset, *Response, error)
+	GetComponent(string, Revision, RevisionComponent, string) ([]byte, *Response, error)
+	GetResourceFile(string, Revision, string, string) ([]byte, *Response, error)
+	GetRevision(string, Revision) (*DeployableRevision, *Response, error)
 	GetDeployments(string) (*Deployment, *Response, error)
 	Import(string, string) (*DeployableRevision, *Response, error)
+	ImportWithOptions(string, string, *ImportOptions) (*DeployableRevision, *Response, error)
 	List() ([]string, *Response, error)
+	ListComponents(string, Revision, RevisionComponent) ([]string, *Response, error)
+	ListResourceFiles(string, Revision) ([]ResourceFile, *Response, error)
+	Promote(string, string, string, *PromoteOptions) (*Promotion, *Response, error)
+	Prune(string, *PruneOptions) (*PruneResult, error)
+	PutComponent(string, Revision, RevisionComponent, string, []byte) (*Response, error)
+	PutResourceFile(string, Revision, string, string, []byte) (*Response, error)
 	Undeploy(string, string, Revision) (*RevisionDeployment, *Response, error)
+	UpdateRevision(string, Revision, string, *ImportOptions) (*DeployableRevision, *Response, error)
 }
 
 type SharedFlowsServiceOp struct {
@@ -32,7 +57,11 @@ func (s *SharedFlowsServiceOp) Get(proxyName string) (*DeployableAsset, *Respons
 }
 
 func (s *SharedFlowsServiceOp) Import(proxyName string, source string) (*DeployableRevision, *Response, error) {
-	return s.deployable.Import(s.client, sharedFlowPath, proxyName, source)
+	return s.deployable.Import(s.client, sharedFlowPath, proxyName, source, nil)
+}
+
+func (s *SharedFlowsServiceOp) ImportWithOptions(proxyName string, source string, opts *ImportOptions) (*DeployableRevision, *Response, error) {
+	return s.deployable.Import(s.client, sharedFlowPath, proxyName, source, opts)
 }
 
 func (s *SharedFlowsServiceOp) Export(proxyName string, rev Revision) (string, *Response, error) {
@@ -43,7 +72,17 @@ func (s *SharedFlowsServiceOp) DeleteRevision(proxyName string, rev Revision) (*
 	return s.deployable.DeleteRevision(s.client, sharedFlowPath, proxyName, rev)
 }
 
+// Undeploy refuses, with a DependentsError, to undeploy a shared flow that
+// deployed proxies, shared flows or flow hooks in the environment depend on.
+// ForceUndeploy skips the check.
 func (s *SharedFlowsServiceOp) Undeploy(proxyName, env string, rev Revision) (*RevisionDeployment, *Response, error) {
+	if e := s.CheckDependents(proxyName, env); e != nil {
+		return nil, nil, e
+	}
+	return s.deployable.Undeploy(s.client, sharedFlowPath, proxyName, env, rev)
+}
+
+func (s *SharedFlowsServiceOp) ForceUndeploy(proxyName, env string, rev Revision) (*RevisionDeployment, *Response, error) {
 	return s.deployable.Undeploy(s.client, sharedFlowPath, proxyName, env, rev)
 }
 
@@ -51,10 +90,112 @@ func (s *SharedFlowsServiceOp) Deploy(proxyName, env string, rev Revision, overr
 	return s.deployable.Deploy(s.client, sharedFlowPath, proxyName, "", env, rev, override, delay)
 }
 
+// Delete refuses, with a DependentsError, to delete a shared flow that anything
+// deployed depends on. ForceDelete skips the check.
 func (s *SharedFlowsServiceOp) Delete(proxyName string) (*DeletedItemInfo, *Response, error) {
+	if e := s.CheckDependents(proxyName); e != nil {
+		return nil, nil, e
+	}
 	return s.deployable.Delete(s.client, sharedFlowPath, proxyName)
 }
 
+func (s *SharedFlowsServiceOp) ForceDelete(proxyName string) (*DeletedItemInfo, *Response, error) {
+	return s.deployable.Delete(s.client, sharedFlowPath, proxyName)
+}
+
+// CheckDependents returns a DependentsError if deployed proxies, shared flows
+// or flow hooks in the named environments depend on the shared flow. When no
+// environments are named, it checks those the shared flow is deployed to, and
+// none when it is not deployed. Undeploy and Delete make this check. Every
+// revision deployed to the environments is exported to build the dependency
+// graph, so the check can take a while.
+func (s *SharedFlowsServiceOp) CheckDependents(sharedFlowName string, envs ...string) error {
+	if len(envs) == 0 {
+		deployments, _, e := s.deployable.GetDeployments(s.client, sharedFlowPath, sharedFlowName)
+		if e != nil {
+			return e
+		}
+		for _, env := range deployments.Environments {
+			envs = append(envs, env.Name)
+		}
+		if len(envs) == 0 {
+			return nil
+		}
+	}
+	g, e := s.client.Environments.GetDependencyGraph(envs...)
+	if e != nil {
+		return fmt.Errorf("while checking dependents of %s, error: %v", sharedFlowName, e)
+	}
+	dependents := []DependencyNode{}
+	for _, env := range envs {
+		dependents = append(dependents, g.Dependents(SharedFlowNode, sharedFlowName, env)...)
+	}
+	if len(dependents) > 0 {
+		return &DependentsError{SharedFlow: sharedFlowName, Dependents: dependents}
+	}
+	return nil
+}
+
 func (s *SharedFlowsServiceOp) GetDeployments(proxyName string) (*Deployment, *Response, error) {
 	return s.deployable.GetDeployments(s.client, sharedFlowPath, proxyName)
 }
+
+func (s *SharedFlowsServiceOp) ExportBundle(proxyName string, rev Revision) (*bundle.Bundle, *Response, error) {
+	return s.deployable.ExportBundle(s.client, sharedFlowPath, proxyName, rev)
+}
+
+func (s *SharedFlowsServiceOp) DiffRevisions(proxyName string, from, to Revision) (*bundle.Diff, *Response, error) {
+	return s.deployable.DiffRevisions(s.client, sharedFlowPath, proxyName, from, to)
+}
+
+func (s *SharedFlowsServiceOp) DiffSource(proxyName string, rev Revision, source string) (*bundle.Diff, *Response, error) {
+	return s.deployable.DiffSource(s.client, sharedFlowPath, proxyName, rev, source)
+}
+
+func (s *SharedFlowsServiceOp) UpdateRevision(proxyName string, rev Revision, source string, opts *ImportOptions) (*DeployableRevision, *Response, error) {
+	return s.deployable.UpdateRevision(s.client, sharedFlowPath, proxyName, rev, source, opts)
+}
+
+func (s *SharedFlowsServiceOp) GetRevision(proxyName string, rev Revision) (*DeployableRevision, *Response, error) {
+	return s.deployable.GetRevision(s.client, sharedFlowPath, proxyName, rev)
+}
+
+func (s *SharedFlowsServiceOp) ListComponents(proxyName string, rev Revision, component RevisionComponent) ([]string, *Response, error) {
+	return s.deployable.ListRevisionComponents(s.client, sharedFlowPath, proxyName, rev, component)
+}
+
+func (s *SharedFlowsServiceOp) GetComponent(proxyName string, rev Revision, component RevisionComponent, name string) ([]byte, *Response, error) {
+	return s.deployable.GetRevisionComponent(s.client, sharedFlowPath, proxyName, rev, component, name)
+}
+
+func (s *SharedFlowsServiceOp) PutComponent(proxyName string, rev Revision, component RevisionComponent, name string, content []byte) (*Response, error) {
+	return s.deployable.PutRevisionComponent(s.client, sharedFlowPath, proxyName, rev, component, name, content)
+}
+
+func (s *SharedFlowsServiceOp) DeleteComponent(proxyName string, rev Revision, component RevisionComponent, name string) (*Response, error) {
+	return s.deployable.DeleteRevisionComponent(s.client, sharedFlowPath, proxyName, rev, component, name)
+}
+
+func (s *SharedFlowsServiceOp) ListResourceFiles(proxyName string, rev Revision) ([]ResourceFile, *Response, error) {
+	return s.deployable.ListResourceFiles(s.client, sharedFlowPath, proxyName, rev)
+}
+
+func (s *SharedFlowsServiceOp) GetResourceFile(proxyName string, rev Revision, resourceType string, name string) ([]byte, *Response, error) {
+	return s.deployable.GetResourceFile(s.client, sharedFlowPath, proxyName, rev, resourceType, name)
+}
+
+func (s *SharedFlowsServiceOp) PutResourceFile(proxyName string, rev Revision, resourceType string, name string, content []byte) (*Response, error) {
+	return s.deployable.PutResourceFile(s.client, sharedFlowPath, proxyName, rev, resourceType, name, content)
+}
+
+func (s *SharedFlowsServiceOp) DeleteResourceFile(proxyName string, rev Revision, resourceType string, name string) (*Response, error) {
+	return s.deployable.DeleteResourceFile(s.client, sharedFlowPath, proxyName, rev, resourceType, name)
+}
+
+func (s *SharedFlowsServiceOp) Prune(proxyName string, opts *PruneOptions) (*PruneResult, error) {
+	return s.deployable.Prune(s.client, sharedFlowPath, proxyName, opts)
+}
+
+func (s *SharedFlowsServiceOp) Promote(proxyName, sourceEnv, targetEnv string, opts *PromoteOptions) (*Promotion, *Response, error) {
+	return s.deployable.Promote(s.client, sharedFlowPath, proxyName, sourceEnv, targetEnv, opts)
+}
@@ -0,0 +1,409 @@
+// Package bundle provides an offline model of Apigee API proxy and shared flow
+// bundles. A bundle can be read from an exploded directory or a zip file,
+// inspected through typed structures, and written back out.
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kind distinguishes API proxy bundles from shared flow bundles. The value is
+// the name of the root directory within the bundle.
+type Kind string
+
+const (
+	ProxyBundle      Kind = "apiproxy"
+	SharedFlowBundle Kind = "sharedflowbundle"
+)
+
+func (k Kind) String() string {
+	return string(k)
+}
+
+// descriptorElement returns the name of the root element of the descriptor.
+func (k Kind) descriptorElement() string {
+	if k == SharedFlowBundle {
+		return "SharedFlowBundle"
+	}
+	return "APIProxy"
+}
+
+// File is a single file within a bundle. Path is slash-separated and relative
+// to the bundle root directory, eg "proxies/endpoint1.xml". Content holds the
+// bytes as read. The typed bundle components embed a File; when its Content is
+// nil, the component is marshaled from its fields on write.
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// Bundle is the parsed form of an API proxy or shared flow bundle.
+type Bundle struct {
+	Kind            Kind
+	Descriptor      *Descriptor
+	ProxyEndpoints  []*ProxyEndpoint
+	TargetEndpoints []*TargetEndpoint
+	SharedFlows     []*SharedFlow
+	Policies        []*Policy
+	Resources       []*Resource
+
+	// Other holds any files that are not one of the recognized components,
+	// so that they survive a round trip.
+	Other []*File
+}
+
+// Load reads a bundle from source, which can be either a zip file, a directory
+// containing an exploded apiproxy or sharedflowbundle directory, or the
+// apiproxy or sharedflowbundle directory itself.
+func Load(source string) (*Bundle, error) {
+	info, e := os.Stat(source)
+	if e != nil {
+		return nil, e
+	}
+	if info.IsDir() {
+		return ReadDir(source)
+	}
+	content, e := ioutil.ReadFile(source)
+	if e != nil {
+		return nil, e
+	}
+	return ReadZip(bytes.NewReader(content), int64(len(content)))
+}
+
+// ReadDir reads an exploded bundle from a filesystem directory. The directory
+// may be the bundle root (named apiproxy or sharedflowbundle) or its parent.
+func ReadDir(dir string) (*Bundle, error) {
+	kind, root, e := findRoot(dir)
+	if e != nil {
+		return nil, e
+	}
+	files := []*File{}
+	e = filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files = append(files, &File{Path: filepath.ToSlash(rel), Content: content})
+		return nil
+	})
+	if e != nil {
+		return nil, e
+	}
+	return Parse(kind, files)
+}
+
+func findRoot(dir string) (Kind, string, error) {
+	for _, kind := range []Kind{ProxyBundle, SharedFlowBundle} {
+		if filepath.Base(dir) == kind.String() {
+			return kind, dir, nil
+		}
+	}
+	for _, kind := range []Kind{ProxyBundle, SharedFlowBundle} {
+		root := filepath.Join(dir, kind.String())
+		if info, e := os.Stat(root); e == nil && info.IsDir() {
+			return kind, root, nil
+		}
+	}
+	return "", "", fmt.Errorf("no apiproxy or sharedflowbundle directory found in %s", dir)
+}
+
+// ReadZip reads a bundle from a zip archive. Files outside the apiproxy or
+// sharedflowbundle directory, like a README at the top level, are ignored.
+func ReadZip(r io.ReaderAt, size int64) (*Bundle, error) {
+	archive, e := zip.NewReader(r, size)
+	if e != nil {
+		return nil, e
+	}
+	var kind Kind
+	files := []*File{}
+	for _, entry := range archive.File {
+		if strings.HasSuffix(entry.Name, "/") {
+			continue
+		}
+		name := strings.TrimPrefix(path.Clean(filepath.ToSlash(entry.Name)), "/")
+		parts := strings.SplitN(name, "/", 2)
+		if len(parts) != 2 || (Kind(parts[0]) != ProxyBundle && Kind(parts[0]) != SharedFlowBundle) {
+			continue
+		}
+		if kind == "" {
+			kind = Kind(parts[0])
+		} else if kind != Kind(parts[0]) {
+			return nil, errors.New("zip contains both apiproxy and sharedflowbundle")
+		}
+		content, e := readZipEntry(entry)
+		if e != nil {
+			return nil, e
+		}
+		files = append(files, &File{Path: parts[1], Content: content})
+	}
+	if kind == "" {
+		return nil, errors.New("zip contains no bundle files")
+	}
+	return Parse(kind, files)
+}
+
+func readZipEntry(entry *zip.File) ([]byte, error) {
+	rc, e := entry.Open()
+	if e != nil {
+		return nil, e
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+// Parse builds a Bundle of the given kind from a set of files, with paths
+// relative to the bundle root.
+func Parse(kind Kind, files []*File) (*Bundle, error) {
+	b := &Bundle{Kind: kind}
+	for _, f := range files {
+		dir, name := path.Split(f.Path)
+		dir = strings.TrimSuffix(dir, "/")
+		isXML := strings.HasSuffix(name, ".xml")
+		var e error
+		switch {
+		case dir == "" && isXML:
+			if b.Descriptor != nil {
+				return nil, fmt.Errorf("multiple descriptors: %s and %s", b.Descriptor.Path, f.Path)
+			}
+			b.Descriptor, e = ParseDescriptor(f.Path, f.Content)
+		case dir == "proxies" && isXML && kind == ProxyBundle:
+			var pe *ProxyEndpoint
+			pe, e = ParseProxyEndpoint(f.Path, f.Content)
+			b.ProxyEndpoints = append(b.ProxyEndpoints, pe)
+		case dir == "targets" && isXML && kind == ProxyBundle:
+			var te *TargetEndpoint
+			te, e = ParseTargetEndpoint(f.Path, f.Content)
+			b.TargetEndpoints = append(b.TargetEndpoints, te)
+		case dir == "sharedflows" && isXML && kind == SharedFlowBundle:
+			var sf *SharedFlow
+			sf, e = ParseSharedFlow(f.Path, f.Content)
+			b.SharedFlows = append(b.SharedFlows, sf)
+		case dir == "policies" && isXML:
+			var p *Policy
+			p, e = ParsePolicy(f.Path, f.Content)
+			b.Policies = append(b.Policies, p)
+		case strings.HasPrefix(dir, "resources/"):
+			b.Resources = append(b.Resources, newResource(f))
+		default:
+			b.Other = append(b.Other, &File{Path: f.Path, Content: f.Content})
+		}
+		if e != nil {
+			return nil, fmt.Errorf("while parsing %s, error: %v", f.Path, e)
+		}
+	}
+	if b.Descriptor == nil {
+		return nil, fmt.Errorf("no descriptor found in %s", kind)
+	}
+	return b, nil
+}
+
+// Name returns the name of the bundle as recorded in the descriptor.
+func (b *Bundle) Name() string {
+	return b.Descriptor.Name
+}
+
+// Policy returns the policy with the given name, or nil.
+func (b *Bundle) Policy(name string) *Policy {
+	for _, p := range b.Policies {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// PoliciesByType groups the policies in the bundle by policy type, eg
+// "AssignMessage" or "Javascript".
+func (b *Bundle) PoliciesByType() map[string][]*Policy {
+	m := map[string][]*Policy{}
+	for _, p := range b.Policies {
+		m[p.Type()] = append(m[p.Type()], p)
+	}
+	return m
+}
+
+// ProxyEndpoint returns the proxy endpoint with the given name, or nil.
+func (b *Bundle) ProxyEndpoint(name string) *ProxyEndpoint {
+	for _, pe := range b.ProxyEndpoints {
+		if pe.Name == name {
+			return pe
+		}
+	}
+	return nil
+}
+
+// TargetEndpoint returns the target endpoint with the given name, or nil.
+func (b *Bundle) TargetEndpoint(name string) *TargetEndpoint {
+	for _, te := range b.TargetEndpoints {
+		if te.Name == name {
+			return te
+		}
+	}
+	return nil
+}
+
+// Resource returns the resource referred to by a URL like
+// "jsc://insertResponseHeader.js", or nil.
+func (b *Bundle) Resource(url string) *Resource {
+	for _, r := range b.Resources {
+		if r.URL() == url {
+			return r
+		}
+	}
+	return nil
+}
+
+// Files returns the serialized form of every file in the bundle, sorted by path.
+// Components that were read from a file are returned byte-for-byte as read;
+// components built in memory are marshaled.
+func (b *Bundle) Files() ([]*File, error) {
+	files := []*File{}
+	add := func(f File, v interface{}) error {
+		if f.Content == nil {
+			content, e := marshal(v)
+			if e != nil {
+				return fmt.Errorf("while marshaling %s, error: %v", f.Path, e)
+			}
+			f.Content = content
+		}
+		files = append(files, &File{Path: f.Path, Content: f.Content})
+		return nil
+	}
+	if b.Descriptor != nil {
+		if b.Descriptor.XMLName.Local == "" {
+			b.Descriptor.XMLName.Local = b.Kind.descriptorElement()
+		}
+		if e := add(b.Descriptor.File, b.Descriptor); e != nil {
+			return nil, e
+		}
+	}
+	for _, pe := range b.ProxyEndpoints {
+		if e := add(pe.File, pe); e != nil {
+			return nil, e
+		}
+	}
+	for _, te := range b.TargetEndpoints {
+		if e := add(te.File, te); e != nil {
+			return nil, e
+		}
+	}
+	for _, sf := range b.SharedFlows {
+		if e := add(sf.File, sf); e != nil {
+			return nil, e
+		}
+	}
+	for _, p := range b.Policies {
+		if e := add(p.File, p); e != nil {
+			return nil, e
+		}
+	}
+	for _, r := range b.Resources {
+		files = append(files, &File{Path: r.Path, Content: r.Content})
+	}
+	for _, f := range b.Other {
+		files = append(files, &File{Path: f.Path, Content: f.Content})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	for i := 1; i < len(files); i++ {
+		if files[i].Path == files[i-1].Path {
+			return nil, fmt.Errorf("duplicate file %s in bundle", files[i].Path)
+		}
+	}
+	return files, nil
+}
+
+// WriteDir writes the bundle as an exploded directory, creating
+// dir/apiproxy or dir/sharedflowbundle.
+func (b *Bundle) WriteDir(dir string) error {
+	files, e := b.Files()
+	if e != nil {
+		return e
+	}
+	root := filepath.Join(dir, b.Kind.String())
+	for _, f := range files {
+		target := filepath.Join(root, filepath.FromSlash(f.Path))
+		if e := os.MkdirAll(filepath.Dir(target), 0755); e != nil {
+			return e
+		}
+		if e := ioutil.WriteFile(target, f.Content, 0644); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// zipEpoch is the modification time recorded for every entry written by
+// WriteZipEntries, so that identical bundles produce identical archives.
+var zipEpoch = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ZipEntry is a file or directory to be written by WriteZipEntries. The name of
+// a directory ends with a slash, and its content is ignored.
+type ZipEntry struct {
+	Name    string
+	Content []byte
+}
+
+// WriteZip writes the bundle as a zip archive suitable for import.
+func (b *Bundle) WriteZip(w io.Writer) error {
+	files, e := b.Files()
+	if e != nil {
+		return e
+	}
+	entries := []ZipEntry{}
+	for _, f := range files {
+		entries = append(entries, ZipEntry{Name: path.Join(b.Kind.String(), f.Path), Content: f.Content})
+	}
+	return WriteZipEntries(w, entries)
+}
+
+// WriteZipEntries writes the entries, in the order given, as a zip archive
+// suitable for import. Timestamps and permissions are fixed, so the same
+// entries always produce byte-identical archives.
+func WriteZipEntries(w io.Writer, entries []ZipEntry) error {
+	archive := zip.NewWriter(w)
+	for _, entry := range entries {
+		isDir := strings.HasSuffix(entry.Name, "/")
+		header := &zip.FileHeader{Name: entry.Name, Modified: zipEpoch}
+		// This archive will be unzipped by a Java process.  When ZIP64 extensions
+		// are used, Java insists on having Deflate as the compression method (0x08)
+		// even for directories.
+		header.Method = zip.Deflate
+		if isDir {
+			header.SetMode(os.ModeDir | 0755)
+		} else {
+			header.SetMode(0644)
+		}
+		writer, e := archive.CreateHeader(header)
+		if e != nil {
+			return e
+		}
+		if isDir {
+			continue
+		}
+		if _, e := writer.Write(entry.Content); e != nil {
+			return e
+		}
+	}
+	return archive.Close()
+}
@@ -0,0 +1,205 @@
+package bundle
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	proxyBundleDir = "../testdata/proxybundles"
+	libraryBundle  = proxyBundleDir + "/apiproxy-library"
+	resourceBundle = proxyBundleDir + "/apiproxy-resourcetest1"
+)
+
+func loadForTesting(t *testing.T, source string) *Bundle {
+	b, e := Load(source)
+	if e != nil {
+		t.Fatalf("while loading %s, error:\n%#v\n", source, e)
+	}
+	return b
+}
+
+func TestLoadDirectory(t *testing.T) {
+	b := loadForTesting(t, libraryBundle)
+	if b.Kind != ProxyBundle {
+		t.Errorf("kind: got=%s", b.Kind)
+	}
+	if b.Name() != "library" {
+		t.Errorf("name: got=%s", b.Name())
+	}
+	if len(b.Descriptor.Policies) != 7 {
+		t.Errorf("descriptor policies: got=%v", b.Descriptor.Policies)
+	}
+
+	pe := b.ProxyEndpoint("endpoint1")
+	if pe == nil {
+		t.Fatalf("no endpoint1 in %#v", b.ProxyEndpoints)
+	}
+	if pe.BasePath() != "/v1/library" {
+		t.Errorf("basepath: got=%s", pe.BasePath())
+	}
+	if len(pe.Flows) != 2 {
+		t.Fatalf("flows: got=%d", len(pe.Flows))
+	}
+	flow := pe.Flows[0]
+	if flow.Name != "addBook" || !strings.Contains(flow.Condition, `MatchesPath "/book"`) {
+		t.Errorf("flow: got=%#v", flow)
+	}
+	if len(flow.Request.Steps) != 2 || flow.Request.Steps[1].Name != "addBook-build-soap" {
+		t.Errorf("flow steps: got=%#v", flow.Request.Steps)
+	}
+	if len(pe.RouteRules) != 1 || pe.RouteRules[0].TargetEndpoint != "library-soap" {
+		t.Errorf("route rules: got=%#v", pe.RouteRules)
+	}
+
+	te := b.TargetEndpoint("library-soap")
+	if te == nil {
+		t.Fatalf("no library-soap in %#v", b.TargetEndpoints)
+	}
+	if te.HTTPTargetConnection == nil || te.HTTPTargetConnection.URL != "https://library-soap.herokuapp.com/Library" {
+		t.Errorf("target connection: got=%#v", te.HTTPTargetConnection)
+	}
+	if len(te.Steps()) != 4 {
+		t.Errorf("target steps: got=%#v", te.Steps())
+	}
+
+	byType := b.PoliciesByType()
+	if len(byType["AssignMessage"]) != 3 || len(byType["ExtractVariables"]) != 2 {
+		t.Errorf("policies by type: got=%v", byType)
+	}
+	if p := b.Policy("Unknown-Resource"); p == nil || p.Type() != "RaiseFault" || p.DisplayName != "Unknown Resource" {
+		t.Errorf("policy: got=%#v", p)
+	}
+}
+
+func TestLoadResources(t *testing.T) {
+	b := loadForTesting(t, filepath.Join(resourceBundle, "apiproxy"))
+	r := b.Resource("jsc://insertResponseHeader.js")
+	if r == nil {
+		t.Fatalf("no resource in %#v", b.Resources)
+	}
+	if r.Type != "jsc" || !bytes.Contains(r.Content, []byte("DinoWasHere")) {
+		t.Errorf("resource: got=%#v", r)
+	}
+	p := b.Policy("JS-InsertResponseHeader")
+	if p == nil || p.Type() != "Javascript" || p.ResourceURL != r.URL() {
+		t.Errorf("policy: got=%#v", p)
+	}
+	if pe := b.ProxyEndpoint("endpoint1"); pe == nil || pe.RouteRules[0].TargetEndpoint != "" {
+		t.Errorf("route rule: got=%#v", pe)
+	}
+}
+
+func TestLoadZip(t *testing.T) {
+	entries, e := ioutil.ReadDir(proxyBundleDir)
+	if e != nil {
+		t.Fatalf("while reading testdata directory, error:\n%#v\n", e)
+	}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".zip") {
+			continue
+		}
+		b := loadForTesting(t, filepath.Join(proxyBundleDir, entry.Name()))
+		if b.Name() == "" || len(b.ProxyEndpoints) == 0 {
+			t.Errorf("%s: got=%#v", entry.Name(), b)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, source := range []string{libraryBundle, resourceBundle} {
+		b := loadForTesting(t, source)
+		tempDir, e := ioutil.TempDir("", "go-apigee-bundle-")
+		if e != nil {
+			t.Fatalf("while creating temp dir, error:\n%#v\n", e)
+		}
+		defer os.RemoveAll(tempDir)
+
+		if e := b.WriteDir(tempDir); e != nil {
+			t.Fatalf("while writing %s, error:\n%#v\n", source, e)
+		}
+		compareTrees(t, filepath.Join(source, "apiproxy"), filepath.Join(tempDir, "apiproxy"))
+
+		buf := new(bytes.Buffer)
+		if e := b.WriteZip(buf); e != nil {
+			t.Fatalf("while zipping %s, error:\n%#v\n", source, e)
+		}
+		fromZip, e := ReadZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if e != nil {
+			t.Fatalf("while reading zip of %s, error:\n%#v\n", source, e)
+		}
+		original, _ := b.Files()
+		copied, _ := fromZip.Files()
+		if len(original) != len(copied) {
+			t.Fatalf("%s: files in zip: got=%d, expected=%d", source, len(copied), len(original))
+		}
+		for i := range original {
+			if original[i].Path != copied[i].Path || !bytes.Equal(original[i].Content, copied[i].Content) {
+				t.Errorf("%s: zip content differs for %s", source, original[i].Path)
+			}
+		}
+	}
+}
+
+func compareTrees(t *testing.T, expectedRoot, actualRoot string) {
+	e := filepath.Walk(expectedRoot, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(expectedRoot, p)
+		expected, _ := ioutil.ReadFile(p)
+		actual, err := ioutil.ReadFile(filepath.Join(actualRoot, rel))
+		if err != nil {
+			t.Errorf("%s: %v", rel, err)
+			return nil
+		}
+		if !bytes.Equal(expected, actual) {
+			t.Errorf("%s: content differs", rel)
+		}
+		return nil
+	})
+	if e != nil {
+		t.Errorf("while comparing trees, error:\n%#v\n", e)
+	}
+}
+
+func TestMarshalNewComponents(t *testing.T) {
+	b := &Bundle{
+		Kind: ProxyBundle,
+		Descriptor: &Descriptor{
+			File:           File{Path: "hello.xml"},
+			Name:           "hello",
+			ProxyEndpoints: []string{"default"},
+		},
+		ProxyEndpoints: []*ProxyEndpoint{{
+			File: File{Path: "proxies/default.xml"},
+			Name: "default",
+			Flows: []Flow{{
+				Name:      "get",
+				Condition: `request.verb = "GET"`,
+				Request:   FlowPhase{Steps: []Step{{Name: "AM-Hello"}}},
+			}},
+			HTTPProxyConnection: &HTTPProxyConnection{BasePath: "/hello", VirtualHosts: []string{"secure"}},
+			RouteRules:          []RouteRule{{Name: "noroute"}},
+		}},
+	}
+	files, e := b.Files()
+	if e != nil {
+		t.Fatalf("while marshaling, error:\n%#v\n", e)
+	}
+	reparsed, e := Parse(ProxyBundle, files)
+	if e != nil {
+		t.Fatalf("while parsing, error:\n%#v\n", e)
+	}
+	pe := reparsed.ProxyEndpoint("default")
+	if pe == nil || pe.BasePath() != "/hello" || pe.Flows[0].Request.Steps[0].Name != "AM-Hello" {
+		t.Errorf("reparsed endpoint: got=%#v", pe)
+	}
+	if reparsed.Descriptor.XMLName.Local != "APIProxy" || reparsed.Name() != "hello" {
+		t.Errorf("reparsed descriptor: got=%#v", reparsed.Descriptor)
+	}
+}
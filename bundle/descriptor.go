@@ -0,0 +1,88 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/xml"
+	"regexp"
+)
+
+// xmlHeader is the declaration Apigee emits at the top of bundle files.
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+// Descriptor is the top-level XML file of a bundle, eg apiproxy/library.xml.
+// The root element is APIProxy for proxies and SharedFlowBundle for shared flows.
+type Descriptor struct {
+	File                 `xml:"-"`
+	XMLName              xml.Name
+	Name                 string                `xml:"name,attr,omitempty"`
+	Revision             string                `xml:"revision,attr,omitempty"`
+	Basepaths            string                `xml:"Basepaths,omitempty"`
+	ConfigurationVersion *ConfigurationVersion `xml:"ConfigurationVersion,omitempty"`
+	CreatedAt            string                `xml:"CreatedAt,omitempty"`
+	CreatedBy            string                `xml:"CreatedBy,omitempty"`
+	Description          string                `xml:"Description,omitempty"`
+	DisplayName          string                `xml:"DisplayName,omitempty"`
+	LastModifiedAt       string                `xml:"LastModifiedAt,omitempty"`
+	LastModifiedBy       string                `xml:"LastModifiedBy,omitempty"`
+	Policies             []string              `xml:"Policies>Policy"`
+	ProxyEndpoints       []string              `xml:"ProxyEndpoints>ProxyEndpoint"`
+	Resources            []string              `xml:"Resources>Resource"`
+	SharedFlows          []string              `xml:"SharedFlows>SharedFlow"`
+	TargetServers        []string              `xml:"TargetServers>TargetServer"`
+	TargetEndpoints      []string              `xml:"TargetEndpoints>TargetEndpoint"`
+}
+
+type ConfigurationVersion struct {
+	MajorVersion string `xml:"majorVersion,attr"`
+	MinorVersion string `xml:"minorVersion,attr"`
+}
+
+// ParseDescriptor parses the bundle descriptor held in content.
+func ParseDescriptor(path string, content []byte) (*Descriptor, error) {
+	d := &Descriptor{File: File{Path: path, Content: content}}
+	if e := xml.Unmarshal(content, d); e != nil {
+		return nil, e
+	}
+	return d, nil
+}
+
+var descriptionElement = regexp.MustCompile(`(?s)<Description\s*/>|<Description>.*?</Description>`)
+
+// SetDescription changes the description of the descriptor. The Description
+// element of Content is rewritten in place, or added at the end, so that the
+// rest of the content, including elements Descriptor does not model, is kept.
+func (d *Descriptor) SetDescription(description string) {
+	d.Description = description
+	if d.Content == nil {
+		return
+	}
+	var element bytes.Buffer
+	element.WriteString("<Description>")
+	_ = xml.EscapeText(&element, []byte(description))
+	element.WriteString("</Description>")
+	if loc := descriptionElement.FindIndex(d.Content); loc != nil {
+		d.Content = concat(d.Content[:loc[0]], element.Bytes(), d.Content[loc[1]:])
+		return
+	}
+	end := bytes.LastIndex(d.Content, []byte("</"))
+	if end < 0 {
+		d.Content = nil
+		return
+	}
+	d.Content = concat(d.Content[:end], element.Bytes(), d.Content[end:])
+}
+
+func concat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	out, e := xml.MarshalIndent(v, "", "    ")
+	if e != nil {
+		return nil, e
+	}
+	buf := bytes.NewBufferString(xmlHeader)
+	buf.Write(out)
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
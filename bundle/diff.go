@@ -0,0 +1,560 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// The status of a file in a FileChange.
+const (
+	FileAdded    = "added"
+	FileRemoved  = "removed"
+	FileModified = "modified"
+)
+
+// The kinds of Change reported by Compare.
+const (
+	ChangePolicyAdded             = "policy-added"
+	ChangePolicyRemoved           = "policy-removed"
+	ChangePolicyModified          = "policy-modified"
+	ChangeProxyEndpointAdded      = "proxy-endpoint-added"
+	ChangeProxyEndpointRemoved    = "proxy-endpoint-removed"
+	ChangeTargetEndpointAdded     = "target-endpoint-added"
+	ChangeTargetEndpointRemoved   = "target-endpoint-removed"
+	ChangeSharedFlowAdded         = "shared-flow-added"
+	ChangeSharedFlowRemoved       = "shared-flow-removed"
+	ChangeBasePath                = "basepath-changed"
+	ChangeFlowAdded               = "flow-added"
+	ChangeFlowRemoved             = "flow-removed"
+	ChangeFlowCondition           = "flow-condition-changed"
+	ChangeFlowSteps               = "flow-steps-changed"
+	ChangeRouteRuleAdded          = "route-rule-added"
+	ChangeRouteRuleRemoved        = "route-rule-removed"
+	ChangeRouteRule               = "route-rule-changed"
+	ChangeTargetURL               = "target-url-changed"
+	ChangeTargetLoadBalancer      = "target-loadbalancer-changed"
+	ChangeResourceAdded           = "resource-added"
+	ChangeResourceRemoved         = "resource-removed"
+	ChangeResourceModified        = "resource-modified"
+	ChangeDescriptorBasePaths     = "descriptor-basepaths-changed"
+	ChangeDescriptorDescription   = "descriptor-description-changed"
+	ChangeDescriptorDisplayName   = "descriptor-displayname-changed"
+	ChangeDescriptorTargetServers = "descriptor-targetservers-changed"
+)
+
+// FileChange records a file that differs between two bundles.
+type FileChange struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+}
+
+// Change records a semantic difference between two bundles. Subject
+// identifies the affected component, eg `proxy endpoint "default" flow "get"`.
+type Change struct {
+	Kind    string `json:"kind"`
+	Subject string `json:"subject"`
+	Old     string `json:"old,omitempty"`
+	New     string `json:"new,omitempty"`
+}
+
+// Diff is the result of comparing two bundles. It can be marshaled to JSON
+// directly, or rendered as text with WriteText.
+type Diff struct {
+	Files   []FileChange `json:"files"`
+	Changes []Change     `json:"changes"`
+}
+
+// Empty reports whether the two bundles were found to be equivalent.
+func (d *Diff) Empty() bool {
+	return len(d.Files) == 0 && len(d.Changes) == 0
+}
+
+// WriteText writes a human-readable form of the diff.
+func (d *Diff) WriteText(w io.Writer) error {
+	_, e := io.WriteString(w, d.String())
+	return e
+}
+
+func (d *Diff) String() string {
+	if d.Empty() {
+		return "no differences\n"
+	}
+	var buf bytes.Buffer
+	buf.WriteString("files:\n")
+	for _, f := range d.Files {
+		fmt.Fprintf(&buf, "  %s %s\n", strings.ToUpper(f.Status[:1]), f.Path)
+	}
+	buf.WriteString("changes:\n")
+	for _, c := range d.Changes {
+		fmt.Fprintf(&buf, "  %s: %s", c.Kind, c.Subject)
+		if c.Old != "" || c.New != "" {
+			fmt.Fprintf(&buf, ": %q -> %q", c.Old, c.New)
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+// Compare returns the differences between two bundles. XML files are compared
+// after canonicalization, so that differences in whitespace, comments and
+// attribute order are ignored.
+func Compare(oldBundle, newBundle *Bundle) (*Diff, error) {
+	d := &Diff{Files: []FileChange{}, Changes: []Change{}}
+	if e := d.compareFiles(oldBundle, newBundle); e != nil {
+		return nil, e
+	}
+	d.compareDescriptors(oldBundle.Descriptor, newBundle.Descriptor)
+	if e := d.comparePolicies(oldBundle, newBundle); e != nil {
+		return nil, e
+	}
+	d.compareProxyEndpoints(oldBundle, newBundle)
+	d.compareTargetEndpoints(oldBundle, newBundle)
+	d.compareSharedFlows(oldBundle, newBundle)
+	d.compareResources(oldBundle, newBundle)
+	return d, nil
+}
+
+func (d *Diff) add(kind, subject, oldValue, newValue string) {
+	d.Changes = append(d.Changes, Change{Kind: kind, Subject: subject, Old: oldValue, New: newValue})
+}
+
+func (d *Diff) compareFiles(oldBundle, newBundle *Bundle) error {
+	oldFiles, e := oldBundle.Files()
+	if e != nil {
+		return e
+	}
+	newFiles, e := newBundle.Files()
+	if e != nil {
+		return e
+	}
+	byPath := map[string]*File{}
+	for _, f := range newFiles {
+		byPath[f.Path] = f
+	}
+	descriptorPath := ""
+	if oldBundle.Descriptor != nil {
+		descriptorPath = oldBundle.Descriptor.Path
+	}
+	for _, f := range oldFiles {
+		other, ok := byPath[f.Path]
+		if !ok {
+			d.Files = append(d.Files, FileChange{Path: f.Path, Status: FileRemoved})
+			continue
+		}
+		delete(byPath, f.Path)
+		var ignore map[string]bool
+		if f.Path == descriptorPath {
+			ignore = descriptorMetadata
+		}
+		same, e := equivalentContent(f.Path, f.Content, other.Content, ignore)
+		if e != nil {
+			return e
+		}
+		if !same {
+			d.Files = append(d.Files, FileChange{Path: f.Path, Status: FileModified})
+		}
+	}
+	for p := range byPath {
+		d.Files = append(d.Files, FileChange{Path: p, Status: FileAdded})
+	}
+	sort.Slice(d.Files, func(i, j int) bool { return d.Files[i].Path < d.Files[j].Path })
+	return nil
+}
+
+// descriptorMetadata names the attribute of the descriptor's root element and
+// the descriptor elements that Edge sets on each revision. An exported revision
+// always has them and a local source usually does not, so they are ignored when
+// comparing descriptors.
+var descriptorMetadata = map[string]bool{
+	"revision":       true,
+	"CreatedAt":      true,
+	"CreatedBy":      true,
+	"LastModifiedAt": true,
+	"LastModifiedBy": true,
+}
+
+// equivalentContent reports whether a and b hold the same content. For XML,
+// attributes of the root element and top-level elements named in ignore are
+// left out of the comparison.
+func equivalentContent(path string, a, b []byte, ignore map[string]bool) (bool, error) {
+	if bytes.Equal(a, b) {
+		return true, nil
+	}
+	if !strings.HasSuffix(path, ".xml") {
+		return false, nil
+	}
+	ca, e := canonicalXMLIgnoring(a, ignore)
+	if e != nil {
+		return false, fmt.Errorf("while reading %s, error: %v", path, e)
+	}
+	cb, e := canonicalXMLIgnoring(b, ignore)
+	if e != nil {
+		return false, fmt.Errorf("while reading %s, error: %v", path, e)
+	}
+	return ca == cb, nil
+}
+
+// canonicalXML renders content with comments, processing instructions and
+// insignificant whitespace removed, and with attributes sorted by name.
+func canonicalXML(content []byte) (string, error) {
+	return canonicalXMLIgnoring(content, nil)
+}
+
+// canonicalXMLIgnoring is canonicalXML, leaving out attributes of the root
+// element and top-level elements whose names are in ignore.
+func canonicalXMLIgnoring(content []byte, ignore map[string]bool) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	decoder.Strict = false
+	var buf bytes.Buffer
+	depth := 0
+	for {
+		token, e := decoder.Token()
+		if e == io.EOF {
+			return buf.String(), nil
+		}
+		if e != nil {
+			return "", e
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth == 1 && ignore[t.Name.Local] {
+				if e := decoder.Skip(); e != nil {
+					return "", e
+				}
+				continue
+			}
+			depth++
+			attrs := []xml.Attr{}
+			for _, attr := range t.Attr {
+				if depth == 1 && ignore[attr.Name.Local] {
+					continue
+				}
+				attrs = append(attrs, attr)
+			}
+			sort.Slice(attrs, func(i, j int) bool {
+				if attrs[i].Name.Space != attrs[j].Name.Space {
+					return attrs[i].Name.Space < attrs[j].Name.Space
+				}
+				return attrs[i].Name.Local < attrs[j].Name.Local
+			})
+			buf.WriteString("<" + qualifiedName(t.Name))
+			for _, attr := range attrs {
+				buf.WriteString(" " + qualifiedName(attr.Name) + `="`)
+				_ = xml.EscapeText(&buf, []byte(attr.Value))
+				buf.WriteString(`"`)
+			}
+			buf.WriteString(">")
+		case xml.EndElement:
+			depth--
+			buf.WriteString("</" + qualifiedName(t.Name) + ">")
+		case xml.CharData:
+			_ = xml.EscapeText(&buf, bytes.TrimSpace(t))
+		}
+	}
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return "{" + name.Space + "}" + name.Local
+}
+
+func (d *Diff) compareDescriptors(oldDesc, newDesc *Descriptor) {
+	if oldDesc == nil || newDesc == nil {
+		return
+	}
+	if oldDesc.Basepaths != newDesc.Basepaths {
+		d.add(ChangeDescriptorBasePaths, "descriptor", oldDesc.Basepaths, newDesc.Basepaths)
+	}
+	if oldDesc.Description != newDesc.Description {
+		d.add(ChangeDescriptorDescription, "descriptor", oldDesc.Description, newDesc.Description)
+	}
+	if oldDesc.DisplayName != newDesc.DisplayName {
+		d.add(ChangeDescriptorDisplayName, "descriptor", oldDesc.DisplayName, newDesc.DisplayName)
+	}
+	oldServers, newServers := joinSorted(oldDesc.TargetServers), joinSorted(newDesc.TargetServers)
+	if oldServers != newServers {
+		d.add(ChangeDescriptorTargetServers, "descriptor", oldServers, newServers)
+	}
+}
+
+func joinSorted(values []string) string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
+
+func (d *Diff) comparePolicies(oldBundle, newBundle *Bundle) error {
+	for _, p := range oldBundle.Policies {
+		subject := fmt.Sprintf("policy %q", p.Name)
+		other := newBundle.Policy(p.Name)
+		if other == nil {
+			d.add(ChangePolicyRemoved, subject, p.Type(), "")
+			continue
+		}
+		oldContent, e := contentOf(p.File, p)
+		if e != nil {
+			return e
+		}
+		newContent, e := contentOf(other.File, other)
+		if e != nil {
+			return e
+		}
+		same, e := equivalentContent(".xml", oldContent, newContent, nil)
+		if e != nil {
+			return fmt.Errorf("while comparing policy %s, error: %v", p.Name, e)
+		}
+		if !same {
+			d.add(ChangePolicyModified, subject, "", "")
+		}
+	}
+	for _, p := range newBundle.Policies {
+		if oldBundle.Policy(p.Name) == nil {
+			d.add(ChangePolicyAdded, fmt.Sprintf("policy %q", p.Name), "", p.Type())
+		}
+	}
+	return nil
+}
+
+func contentOf(f File, v interface{}) ([]byte, error) {
+	if f.Content != nil {
+		return f.Content, nil
+	}
+	return marshal(v)
+}
+
+func (d *Diff) compareProxyEndpoints(oldBundle, newBundle *Bundle) {
+	for _, pe := range oldBundle.ProxyEndpoints {
+		subject := fmt.Sprintf("proxy endpoint %q", pe.Name)
+		other := newBundle.ProxyEndpoint(pe.Name)
+		if other == nil {
+			d.add(ChangeProxyEndpointRemoved, subject, pe.BasePath(), "")
+			continue
+		}
+		if pe.BasePath() != other.BasePath() {
+			d.add(ChangeBasePath, subject, pe.BasePath(), other.BasePath())
+		}
+		d.compareFlows(subject,
+			namedFlows(pe.PreFlow, pe.Flows, pe.PostFlow, pe.PostClientFlow),
+			namedFlows(other.PreFlow, other.Flows, other.PostFlow, other.PostClientFlow))
+		d.compareRouteRules(subject, pe.RouteRules, other.RouteRules)
+	}
+	for _, pe := range newBundle.ProxyEndpoints {
+		if oldBundle.ProxyEndpoint(pe.Name) == nil {
+			d.add(ChangeProxyEndpointAdded, fmt.Sprintf("proxy endpoint %q", pe.Name), "", pe.BasePath())
+		}
+	}
+}
+
+func (d *Diff) compareTargetEndpoints(oldBundle, newBundle *Bundle) {
+	for _, te := range oldBundle.TargetEndpoints {
+		subject := fmt.Sprintf("target endpoint %q", te.Name)
+		other := newBundle.TargetEndpoint(te.Name)
+		if other == nil {
+			d.add(ChangeTargetEndpointRemoved, subject, targetURL(te), "")
+			continue
+		}
+		if targetURL(te) != targetURL(other) {
+			d.add(ChangeTargetURL, subject, targetURL(te), targetURL(other))
+		}
+		if loadBalancerServers(te) != loadBalancerServers(other) {
+			d.add(ChangeTargetLoadBalancer, subject, loadBalancerServers(te), loadBalancerServers(other))
+		}
+		d.compareFlows(subject,
+			namedFlows(te.PreFlow, te.Flows, te.PostFlow, nil),
+			namedFlows(other.PreFlow, other.Flows, other.PostFlow, nil))
+	}
+	for _, te := range newBundle.TargetEndpoints {
+		if oldBundle.TargetEndpoint(te.Name) == nil {
+			d.add(ChangeTargetEndpointAdded, fmt.Sprintf("target endpoint %q", te.Name), "", targetURL(te))
+		}
+	}
+}
+
+func targetURL(te *TargetEndpoint) string {
+	if te.HTTPTargetConnection != nil {
+		return te.HTTPTargetConnection.URL
+	}
+	if te.LocalTargetConnection != nil {
+		if te.LocalTargetConnection.APIProxy != "" {
+			return "proxy://" + te.LocalTargetConnection.APIProxy
+		}
+		return te.LocalTargetConnection.Path
+	}
+	return ""
+}
+
+func loadBalancerServers(te *TargetEndpoint) string {
+	if te.HTTPTargetConnection == nil || te.HTTPTargetConnection.LoadBalancer == nil {
+		return ""
+	}
+	names := []string{}
+	for _, server := range te.HTTPTargetConnection.LoadBalancer.Servers {
+		names = append(names, server.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func (d *Diff) compareSharedFlows(oldBundle, newBundle *Bundle) {
+	find := func(flows []*SharedFlow, name string) *SharedFlow {
+		for _, sf := range flows {
+			if sf.Name == name {
+				return sf
+			}
+		}
+		return nil
+	}
+	for _, sf := range oldBundle.SharedFlows {
+		subject := fmt.Sprintf("shared flow %q", sf.Name)
+		other := find(newBundle.SharedFlows, sf.Name)
+		if other == nil {
+			d.add(ChangeSharedFlowRemoved, subject, "", "")
+			continue
+		}
+		oldSteps, newSteps := describeSteps(sf.Steps), describeSteps(other.Steps)
+		if oldSteps != newSteps {
+			d.add(ChangeFlowSteps, subject, oldSteps, newSteps)
+		}
+	}
+	for _, sf := range newBundle.SharedFlows {
+		if find(oldBundle.SharedFlows, sf.Name) == nil {
+			d.add(ChangeSharedFlowAdded, fmt.Sprintf("shared flow %q", sf.Name), "", "")
+		}
+	}
+}
+
+type namedFlow struct {
+	label string
+	flow  *Flow
+}
+
+func namedFlows(pre *Flow, conditional []Flow, post *Flow, postClient *Flow) []namedFlow {
+	flows := []namedFlow{}
+	if pre != nil {
+		flows = append(flows, namedFlow{"PreFlow", pre})
+	}
+	for i := range conditional {
+		flows = append(flows, namedFlow{fmt.Sprintf("flow %q", conditional[i].Name), &conditional[i]})
+	}
+	if post != nil {
+		flows = append(flows, namedFlow{"PostFlow", post})
+	}
+	if postClient != nil {
+		flows = append(flows, namedFlow{"PostClientFlow", postClient})
+	}
+	return flows
+}
+
+func (d *Diff) compareFlows(subject string, oldFlows, newFlows []namedFlow) {
+	find := func(flows []namedFlow, label string) *Flow {
+		for _, f := range flows {
+			if f.label == label {
+				return f.flow
+			}
+		}
+		return nil
+	}
+	for _, f := range oldFlows {
+		flowSubject := subject + " " + f.label
+		other := find(newFlows, f.label)
+		if other == nil {
+			d.add(ChangeFlowRemoved, flowSubject, f.flow.Condition, "")
+			continue
+		}
+		if normalizeCondition(f.flow.Condition) != normalizeCondition(other.Condition) {
+			d.add(ChangeFlowCondition, flowSubject, f.flow.Condition, other.Condition)
+		}
+		oldSteps, newSteps := describeFlowSteps(f.flow), describeFlowSteps(other)
+		if oldSteps != newSteps {
+			d.add(ChangeFlowSteps, flowSubject, oldSteps, newSteps)
+		}
+	}
+	for _, f := range newFlows {
+		if find(oldFlows, f.label) == nil {
+			d.add(ChangeFlowAdded, subject+" "+f.label, "", f.flow.Condition)
+		}
+	}
+}
+
+// normalizeCondition collapses runs of whitespace in a condition expression.
+func normalizeCondition(condition string) string {
+	return strings.Join(strings.Fields(condition), " ")
+}
+
+func describeFlowSteps(f *Flow) string {
+	return "request: " + describeSteps(f.Request.Steps) + "; response: " + describeSteps(f.Response.Steps)
+}
+
+func describeSteps(steps []Step) string {
+	parts := []string{}
+	for _, step := range steps {
+		if step.Condition == "" {
+			parts = append(parts, step.Name)
+		} else {
+			parts = append(parts, fmt.Sprintf("%s [%s]", step.Name, normalizeCondition(step.Condition)))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (d *Diff) compareRouteRules(subject string, oldRules, newRules []RouteRule) {
+	find := func(rules []RouteRule, name string) *RouteRule {
+		for i := range rules {
+			if rules[i].Name == name {
+				return &rules[i]
+			}
+		}
+		return nil
+	}
+	describe := func(r *RouteRule) string {
+		destination := r.TargetEndpoint
+		if r.URL != "" {
+			destination = r.URL
+		}
+		if destination == "" {
+			destination = "(none)"
+		}
+		if condition := normalizeCondition(r.Condition); condition != "" {
+			return destination + " when " + condition
+		}
+		return destination
+	}
+	for i := range oldRules {
+		ruleSubject := fmt.Sprintf("%s route rule %q", subject, oldRules[i].Name)
+		other := find(newRules, oldRules[i].Name)
+		if other == nil {
+			d.add(ChangeRouteRuleRemoved, ruleSubject, describe(&oldRules[i]), "")
+			continue
+		}
+		if describe(&oldRules[i]) != describe(other) {
+			d.add(ChangeRouteRule, ruleSubject, describe(&oldRules[i]), describe(other))
+		}
+	}
+	for i := range newRules {
+		if find(oldRules, newRules[i].Name) == nil {
+			d.add(ChangeRouteRuleAdded, fmt.Sprintf("%s route rule %q", subject, newRules[i].Name), "", describe(&newRules[i]))
+		}
+	}
+}
+
+func (d *Diff) compareResources(oldBundle, newBundle *Bundle) {
+	for _, r := range oldBundle.Resources {
+		subject := fmt.Sprintf("resource %q", r.URL())
+		other := newBundle.Resource(r.URL())
+		if other == nil {
+			d.add(ChangeResourceRemoved, subject, "", "")
+		} else if !bytes.Equal(r.Content, other.Content) {
+			d.add(ChangeResourceModified, subject, "", "")
+		}
+	}
+	for _, r := range newBundle.Resources {
+		if oldBundle.Resource(r.URL()) == nil {
+			d.add(ChangeResourceAdded, fmt.Sprintf("resource %q", r.URL()), "", "")
+		}
+	}
+}
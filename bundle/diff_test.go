@@ -0,0 +1,162 @@
+package bundle
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func modifiedLibrary(t *testing.T) *Bundle {
+	b := loadForTesting(t, libraryBundle)
+	files, e := b.Files()
+	if e != nil {
+		t.Fatalf("while getting files, error:\n%#v\n", e)
+	}
+	modified := []*File{}
+	for _, f := range files {
+		content := string(f.Content)
+		switch f.Path {
+		case "proxies/endpoint1.xml":
+			content = strings.Replace(content, `(request.verb = "POST")`, `(request.verb = "PUT")`, 1)
+		case "targets/library-soap.xml":
+			content = strings.Replace(content, "https://library-soap.herokuapp.com/Library", "https://library.example.com/Library", 1)
+		case "policies/Xml-to-Json.xml":
+			// reordered attributes and different whitespace only
+			content = `<XMLToJSON name="Xml-to-Json" enabled="true" continueOnError="false" async="false">
+  <DisplayName>XML to JSON</DisplayName>  <Format>yahoo</Format>
+  <OutputVariable>response</OutputVariable>
+  <!-- same as before -->
+  <Source>response</Source>
+</XMLToJSON>`
+		case "policies/Unknown-Resource.xml":
+			continue
+		}
+		modified = append(modified, &File{Path: f.Path, Content: []byte(content)})
+	}
+	modified = append(modified, &File{Path: "policies/AM-New.xml", Content: []byte(`<AssignMessage name="AM-New"/>`)})
+	newBundle, e := Parse(ProxyBundle, modified)
+	if e != nil {
+		t.Fatalf("while parsing, error:\n%#v\n", e)
+	}
+	return newBundle
+}
+
+func TestCompareIdentical(t *testing.T) {
+	d, e := Compare(loadForTesting(t, libraryBundle), loadForTesting(t, libraryBundle))
+	if e != nil {
+		t.Fatalf("while comparing, error:\n%#v\n", e)
+	}
+	if !d.Empty() {
+		t.Errorf("expected no differences, got:\n%s", d)
+	}
+}
+
+func TestCompareExportedRevision(t *testing.T) {
+	// An exported revision has revision and timestamp metadata in its
+	// descriptor that its source lacks.
+	withDescriptor := func(edit func(string) string) *Bundle {
+		b := loadForTesting(t, libraryBundle)
+		files, e := b.Files()
+		if e != nil {
+			t.Fatalf("while getting files, error:\n%#v\n", e)
+		}
+		for _, f := range files {
+			if f.Path == b.Descriptor.Path {
+				f.Content = []byte(edit(string(f.Content)))
+			}
+		}
+		edited, e := Parse(ProxyBundle, files)
+		if e != nil {
+			t.Fatalf("while parsing, error:\n%#v\n", e)
+		}
+		return edited
+	}
+	source := withDescriptor(func(content string) string {
+		lines := []string{}
+		for _, line := range strings.Split(content, "\n") {
+			if !strings.Contains(line, "CreatedAt") && !strings.Contains(line, "CreatedBy") &&
+				!strings.Contains(line, "LastModifiedAt") && !strings.Contains(line, "LastModifiedBy") {
+				lines = append(lines, line)
+			}
+		}
+		return strings.Replace(strings.Join(lines, "\n"), ` revision="1"`, "", 1)
+	})
+	exported := withDescriptor(func(content string) string {
+		content = strings.Replace(content, `revision="1"`, `revision="7"`, 1)
+		return strings.Replace(content, "<LastModifiedAt>1389292745369<", "<LastModifiedAt>1612345678901<", 1)
+	})
+	d, e := Compare(exported, source)
+	if e != nil {
+		t.Fatalf("while comparing, error:\n%#v\n", e)
+	}
+	if !d.Empty() {
+		t.Errorf("expected no differences, got:\n%s", d)
+	}
+
+	described := withDescriptor(func(content string) string {
+		return strings.Replace(content, "<Description></Description>", "<Description>changed</Description>", 1)
+	})
+	d, e = Compare(exported, described)
+	if e != nil {
+		t.Fatalf("while comparing, error:\n%#v\n", e)
+	}
+	if len(d.Files) != 1 || d.Files[0].Path != exported.Descriptor.Path {
+		t.Errorf("files: got=%v", d.Files)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	d, e := Compare(loadForTesting(t, libraryBundle), modifiedLibrary(t))
+	if e != nil {
+		t.Fatalf("while comparing, error:\n%#v\n", e)
+	}
+	t.Logf("\n%s", d)
+
+	expectedFiles := []FileChange{
+		{"policies/AM-New.xml", FileAdded},
+		{"policies/Unknown-Resource.xml", FileRemoved},
+		{"proxies/endpoint1.xml", FileModified},
+		{"targets/library-soap.xml", FileModified},
+	}
+	if len(d.Files) != len(expectedFiles) {
+		t.Fatalf("files: got=%v", d.Files)
+	}
+	for i := range expectedFiles {
+		if d.Files[i] != expectedFiles[i] {
+			t.Errorf("file %d: got=%v, expected=%v", i, d.Files[i], expectedFiles[i])
+		}
+	}
+
+	expectedChanges := map[string]Change{
+		ChangePolicyRemoved: {Subject: `policy "Unknown-Resource"`, Old: "RaiseFault"},
+		ChangePolicyAdded:   {Subject: `policy "AM-New"`, New: "AssignMessage"},
+		ChangeFlowCondition: {
+			Subject: `proxy endpoint "endpoint1" flow "addBook"`,
+			Old:     `(proxy.pathsuffix MatchesPath "/book") and (request.verb = "POST")`,
+			New:     `(proxy.pathsuffix MatchesPath "/book") and (request.verb = "PUT")`,
+		},
+		ChangeTargetURL: {
+			Subject: `target endpoint "library-soap"`,
+			Old:     "https://library-soap.herokuapp.com/Library",
+			New:     "https://library.example.com/Library",
+		},
+	}
+	if len(d.Changes) != len(expectedChanges) {
+		t.Fatalf("changes: got=%v", d.Changes)
+	}
+	for _, c := range d.Changes {
+		want, ok := expectedChanges[c.Kind]
+		if !ok || c.Subject != want.Subject || c.Old != want.Old || c.New != want.New {
+			t.Errorf("change: got=%+v, expected=%+v", c, want)
+		}
+	}
+
+	out, e := json.Marshal(d)
+	if e != nil {
+		t.Fatalf("while marshaling, error:\n%#v\n", e)
+	}
+	var roundTrip Diff
+	if e := json.Unmarshal(out, &roundTrip); e != nil || len(roundTrip.Changes) != len(d.Changes) {
+		t.Errorf("json: got=%s", out)
+	}
+}
@@ -0,0 +1,221 @@
+package bundle
+
+import (
+	"encoding/xml"
+)
+
+// Step is a reference to a policy from within a flow or fault rule.
+type Step struct {
+	Name      string `xml:"Name"`
+	Condition string `xml:"Condition,omitempty"`
+}
+
+// FlowPhase holds the steps of the Request or Response side of a flow.
+type FlowPhase struct {
+	Steps []Step `xml:"Step"`
+}
+
+// Flow is a PreFlow, PostFlow, PostClientFlow or conditional flow within an endpoint.
+type Flow struct {
+	Name        string    `xml:"name,attr,omitempty"`
+	Description string    `xml:"Description,omitempty"`
+	Request     FlowPhase `xml:"Request"`
+	Response    FlowPhase `xml:"Response"`
+	Condition   string    `xml:"Condition,omitempty"`
+}
+
+// Steps returns the request steps followed by the response steps of the flow.
+func (f *Flow) Steps() []Step {
+	steps := append([]Step{}, f.Request.Steps...)
+	return append(steps, f.Response.Steps...)
+}
+
+// FaultRule holds the steps to execute when a condition holds in the error flow.
+type FaultRule struct {
+	Name          string `xml:"name,attr,omitempty"`
+	AlwaysEnforce string `xml:"AlwaysEnforce,omitempty"`
+	Steps         []Step `xml:"Step"`
+	Condition     string `xml:"Condition,omitempty"`
+}
+
+// RouteRule selects the target endpoint, or target URL, for a request. A rule
+// with neither a TargetEndpoint nor a URL is a "null" route.
+type RouteRule struct {
+	Name           string `xml:"name,attr,omitempty"`
+	Condition      string `xml:"Condition,omitempty"`
+	TargetEndpoint string `xml:"TargetEndpoint,omitempty"`
+	URL            string `xml:"URL,omitempty"`
+}
+
+// Property is a name/value pair used in connection settings.
+type Property struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+type HTTPProxyConnection struct {
+	BasePath     string     `xml:"BasePath"`
+	Properties   []Property `xml:"Properties>Property,omitempty"`
+	VirtualHosts []string   `xml:"VirtualHost"`
+}
+
+// ProxyEndpoint is a file in the proxies directory of an API proxy bundle.
+type ProxyEndpoint struct {
+	File                `xml:"-"`
+	XMLName             xml.Name             `xml:"ProxyEndpoint"`
+	Name                string               `xml:"name,attr"`
+	Description         string               `xml:"Description,omitempty"`
+	FaultRules          []FaultRule          `xml:"FaultRules>FaultRule,omitempty"`
+	DefaultFaultRule    *FaultRule           `xml:"DefaultFaultRule,omitempty"`
+	PreFlow             *Flow                `xml:"PreFlow,omitempty"`
+	Flows               []Flow               `xml:"Flows>Flow,omitempty"`
+	PostFlow            *Flow                `xml:"PostFlow,omitempty"`
+	PostClientFlow      *Flow                `xml:"PostClientFlow,omitempty"`
+	HTTPProxyConnection *HTTPProxyConnection `xml:"HTTPProxyConnection,omitempty"`
+	RouteRules          []RouteRule          `xml:"RouteRule"`
+}
+
+// ParseProxyEndpoint parses the proxy endpoint held in content.
+func ParseProxyEndpoint(path string, content []byte) (*ProxyEndpoint, error) {
+	pe := &ProxyEndpoint{File: File{Path: path, Content: content}}
+	if e := xml.Unmarshal(content, pe); e != nil {
+		return nil, e
+	}
+	return pe, nil
+}
+
+// BasePath returns the base path of the endpoint, or "" if there is no HTTPProxyConnection.
+func (pe *ProxyEndpoint) BasePath() string {
+	if pe.HTTPProxyConnection == nil {
+		return ""
+	}
+	return pe.HTTPProxyConnection.BasePath
+}
+
+// AllFlows returns the PreFlow, the conditional flows, the PostFlow and the
+// PostClientFlow of the endpoint, in execution order, skipping any that are absent.
+func (pe *ProxyEndpoint) AllFlows() []*Flow {
+	return collectFlows(pe.PreFlow, pe.Flows, pe.PostFlow, pe.PostClientFlow)
+}
+
+// Steps returns every step in the endpoint, including those in fault rules.
+func (pe *ProxyEndpoint) Steps() []Step {
+	return collectSteps(pe.AllFlows(), pe.FaultRules, pe.DefaultFaultRule)
+}
+
+type LoadBalancerServer struct {
+	Name string `xml:"name,attr"`
+}
+
+type LoadBalancer struct {
+	Algorithm   string               `xml:"Algorithm,omitempty"`
+	Servers     []LoadBalancerServer `xml:"Server"`
+	MaxFailures string               `xml:"MaxFailures,omitempty"`
+}
+
+type SSLInfo struct {
+	Enabled                string `xml:"Enabled,omitempty"`
+	ClientAuthEnabled      string `xml:"ClientAuthEnabled,omitempty"`
+	KeyStore               string `xml:"KeyStore,omitempty"`
+	KeyAlias               string `xml:"KeyAlias,omitempty"`
+	TrustStore             string `xml:"TrustStore,omitempty"`
+	IgnoreValidationErrors string `xml:"IgnoreValidationErrors,omitempty"`
+}
+
+type HTTPTargetConnection struct {
+	URL          string        `xml:"URL,omitempty"`
+	LoadBalancer *LoadBalancer `xml:"LoadBalancer,omitempty"`
+	Path         string        `xml:"Path,omitempty"`
+	SSLInfo      *SSLInfo      `xml:"SSLInfo,omitempty"`
+	Properties   []Property    `xml:"Properties>Property,omitempty"`
+}
+
+// LocalTargetConnection routes a request to another proxy in the same environment.
+type LocalTargetConnection struct {
+	APIProxy      string `xml:"APIProxy,omitempty"`
+	ProxyEndpoint string `xml:"ProxyEndpoint,omitempty"`
+	Path          string `xml:"Path,omitempty"`
+}
+
+// TargetEndpoint is a file in the targets directory of an API proxy bundle.
+type TargetEndpoint struct {
+	File                  `xml:"-"`
+	XMLName               xml.Name               `xml:"TargetEndpoint"`
+	Name                  string                 `xml:"name,attr"`
+	Description           string                 `xml:"Description,omitempty"`
+	FaultRules            []FaultRule            `xml:"FaultRules>FaultRule,omitempty"`
+	DefaultFaultRule      *FaultRule             `xml:"DefaultFaultRule,omitempty"`
+	PreFlow               *Flow                  `xml:"PreFlow,omitempty"`
+	Flows                 []Flow                 `xml:"Flows>Flow,omitempty"`
+	PostFlow              *Flow                  `xml:"PostFlow,omitempty"`
+	HTTPTargetConnection  *HTTPTargetConnection  `xml:"HTTPTargetConnection,omitempty"`
+	LocalTargetConnection *LocalTargetConnection `xml:"LocalTargetConnection,omitempty"`
+}
+
+// ParseTargetEndpoint parses the target endpoint held in content.
+func ParseTargetEndpoint(path string, content []byte) (*TargetEndpoint, error) {
+	te := &TargetEndpoint{File: File{Path: path, Content: content}}
+	if e := xml.Unmarshal(content, te); e != nil {
+		return nil, e
+	}
+	return te, nil
+}
+
+// AllFlows returns the PreFlow, the conditional flows and the PostFlow of the
+// endpoint, in execution order, skipping any that are absent.
+func (te *TargetEndpoint) AllFlows() []*Flow {
+	return collectFlows(te.PreFlow, te.Flows, te.PostFlow, nil)
+}
+
+// Steps returns every step in the endpoint, including those in fault rules.
+func (te *TargetEndpoint) Steps() []Step {
+	return collectSteps(te.AllFlows(), te.FaultRules, te.DefaultFaultRule)
+}
+
+// SharedFlow is a file in the sharedflows directory of a shared flow bundle.
+type SharedFlow struct {
+	File    `xml:"-"`
+	XMLName xml.Name `xml:"SharedFlow"`
+	Name    string   `xml:"name,attr"`
+	Steps   []Step   `xml:"Step"`
+}
+
+// ParseSharedFlow parses the shared flow held in content.
+func ParseSharedFlow(path string, content []byte) (*SharedFlow, error) {
+	sf := &SharedFlow{File: File{Path: path, Content: content}}
+	if e := xml.Unmarshal(content, sf); e != nil {
+		return nil, e
+	}
+	return sf, nil
+}
+
+func collectFlows(pre *Flow, conditional []Flow, post *Flow, postClient *Flow) []*Flow {
+	flows := []*Flow{}
+	if pre != nil {
+		flows = append(flows, pre)
+	}
+	for i := range conditional {
+		flows = append(flows, &conditional[i])
+	}
+	if post != nil {
+		flows = append(flows, post)
+	}
+	if postClient != nil {
+		flows = append(flows, postClient)
+	}
+	return flows
+}
+
+func collectSteps(flows []*Flow, faultRules []FaultRule, defaultFaultRule *FaultRule) []Step {
+	steps := []Step{}
+	for _, f := range flows {
+		steps = append(steps, f.Steps()...)
+	}
+	for _, fr := range faultRules {
+		steps = append(steps, fr.Steps...)
+	}
+	if defaultFaultRule != nil {
+		steps = append(steps, defaultFaultRule.Steps...)
+	}
+	return steps
+}
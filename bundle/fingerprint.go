@@ -0,0 +1,98 @@
+package bundle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	fingerprintMarker = regexp.MustCompile(`\s*\[fingerprint (sha256:[0-9a-f]{64})\]`)
+	emptyDescription  = regexp.MustCompile(`<Description\s*/>|<Description>\s*</Description>`)
+)
+
+// RecordedFingerprint returns the fingerprint recorded in a description by
+// WithFingerprint, or "" if there is none.
+func RecordedFingerprint(description string) string {
+	m := fingerprintMarker.FindStringSubmatch(description)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// WithFingerprint returns the description with the fingerprint recorded in it,
+// replacing any fingerprint recorded previously.
+func WithFingerprint(description, fingerprint string) string {
+	marker := "[fingerprint " + fingerprint + "]"
+	if fingerprintMarker.MatchString(description) {
+		return strings.TrimSpace(fingerprintMarker.ReplaceAllLiteralString(description, " "+marker))
+	}
+	return strings.TrimSpace(description + " " + marker)
+}
+
+// descriptorGenerated names the descriptor elements left out of a fingerprint:
+// those Edge sets on each revision, and the lists of policies, endpoints,
+// resources and so on that Edge writes from the other files of the bundle,
+// which are fingerprinted themselves.
+var descriptorGenerated = map[string]bool{
+	"ManifestVersion": true,
+	"Policies":        true,
+	"ProxyEndpoints":  true,
+	"Resources":       true,
+	"SharedFlows":     true,
+	"TargetEndpoints": true,
+	"TargetServers":   true,
+}
+
+// Fingerprint returns a digest of the content of the bundle, in the form
+// "sha256:<hex>". XML files are canonicalized first, as for Compare, so
+// formatting changes do not alter the fingerprint. Of the descriptor, what
+// Edge rewrites on import is left out: the revision, the creation and
+// modification details, the generated lists of files, and a fingerprint
+// recorded in the description. A bundle and its exported revision therefore
+// have the same fingerprint, while a change to, say, the display name or
+// description changes it.
+func (b *Bundle) Fingerprint() (string, error) {
+	files, e := b.Files()
+	if e != nil {
+		return "", e
+	}
+	h := sha256.New()
+	for _, f := range files {
+		content := f.Content
+		if b.Descriptor != nil && f.Path == b.Descriptor.Path {
+			canonical, e := canonicalDescriptor(f.Content)
+			if e != nil {
+				return "", fmt.Errorf("while reading %s, error: %v", f.Path, e)
+			}
+			content = []byte(canonical)
+		} else if strings.HasSuffix(f.Path, ".xml") {
+			canonical, e := canonicalXML(f.Content)
+			if e != nil {
+				return "", fmt.Errorf("while reading %s, error: %v", f.Path, e)
+			}
+			content = []byte(canonical)
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", f.Path, len(content))
+		h.Write(content)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// canonicalDescriptor is canonicalXML for a descriptor, without what Edge
+// rewrites on import. An empty description counts as none, so that recording a
+// fingerprint in a descriptor without one does not change it.
+func canonicalDescriptor(content []byte) (string, error) {
+	ignore := map[string]bool{}
+	for name := range descriptorMetadata {
+		ignore[name] = true
+	}
+	for name := range descriptorGenerated {
+		ignore[name] = true
+	}
+	content = fingerprintMarker.ReplaceAll(content, nil)
+	return canonicalXMLIgnoring(emptyDescription.ReplaceAll(content, nil), ignore)
+}
@@ -0,0 +1,107 @@
+package bundle
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	fingerprint1 = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	fingerprint2 = "sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+)
+
+func TestFingerprintDescription(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		description string
+		expected    string
+	}{
+		{"empty", "", "[fingerprint " + fingerprint1 + "]"},
+		{"text", "my proxy", "my proxy [fingerprint " + fingerprint1 + "]"},
+		{"replaced", "my proxy [fingerprint " + fingerprint2 + "]", "my proxy [fingerprint " + fingerprint1 + "]"},
+	}
+	for _, tc := range testCases {
+		got := WithFingerprint(tc.description, fingerprint1)
+		if got != tc.expected {
+			t.Errorf("%s: got=%q, expected=%q", tc.desc, got, tc.expected)
+		}
+		if recorded := RecordedFingerprint(got); recorded != fingerprint1 {
+			t.Errorf("%s: recorded=%q", tc.desc, recorded)
+		}
+	}
+	if recorded := RecordedFingerprint("no fingerprint here"); recorded != "" {
+		t.Errorf("recorded=%q", recorded)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	original := loadForTesting(t, libraryBundle)
+	fingerprint, e := original.Fingerprint()
+	if e != nil {
+		t.Fatalf("while computing fingerprint, error:\n%#v\n", e)
+	}
+	if !strings.HasPrefix(fingerprint, "sha256:") {
+		t.Errorf("fingerprint: got=%s", fingerprint)
+	}
+
+	files, _ := original.Files()
+	reformatted := []*File{}
+	for _, f := range files {
+		content := f.Content
+		if f.Path == "policies/Xml-to-Json.xml" {
+			content = []byte(strings.Replace(string(content), "\n    ", "\n\t", -1))
+		}
+		if f.Path == "library.xml" {
+			content = []byte(strings.Replace(string(content), `revision="1"`, `revision="7"`, 1))
+		}
+		reformatted = append(reformatted, &File{Path: f.Path, Content: content})
+	}
+	same, e := Parse(ProxyBundle, reformatted)
+	if e != nil {
+		t.Fatalf("while parsing, error:\n%#v\n", e)
+	}
+	if got, _ := same.Fingerprint(); got != fingerprint {
+		t.Errorf("reformatted bundle: got=%s, expected=%s", got, fingerprint)
+	}
+
+	if got, _ := modifiedLibrary(t).Fingerprint(); got == fingerprint {
+		t.Errorf("modified bundle has the same fingerprint as the original")
+	}
+
+	descriptorCases := []struct {
+		desc      string
+		old, new  string
+		unchanged bool
+	}{
+		{"display name", "<DisplayName>library</DisplayName>", "<DisplayName>Library v2</DisplayName>", false},
+		{"description", "<Description></Description>", "<Description>books</Description>", false},
+		{"configuration version", `majorVersion="4"`, `majorVersion="5"`, false},
+		{"recorded fingerprint", "<Description></Description>", "<Description>[fingerprint " + fingerprint1 + "]</Description>", true},
+		{"modified by", "<LastModifiedBy>DChiesa@apigee.com</LastModifiedBy>", "<LastModifiedBy>someone@example.com</LastModifiedBy>", true},
+		{"generated list", "<Resources/>", "<Resources><Resource>jsc://a.js</Resource></Resources>", true},
+	}
+	for _, tc := range descriptorCases {
+		edited := []*File{}
+		for _, f := range files {
+			content := f.Content
+			if f.Path == "library.xml" {
+				if !strings.Contains(string(content), tc.old) {
+					t.Fatalf("%s: descriptor has no %s", tc.desc, tc.old)
+				}
+				content = []byte(strings.Replace(string(content), tc.old, tc.new, 1))
+			}
+			edited = append(edited, &File{Path: f.Path, Content: content})
+		}
+		b, e := Parse(ProxyBundle, edited)
+		if e != nil {
+			t.Fatalf("%s: while parsing, error:\n%#v\n", tc.desc, e)
+		}
+		got, e := b.Fingerprint()
+		if e != nil {
+			t.Fatalf("%s: while computing fingerprint, error:\n%#v\n", tc.desc, e)
+		}
+		if (got == fingerprint) != tc.unchanged {
+			t.Errorf("%s: got=%s, original=%s, expected unchanged=%v", tc.desc, got, fingerprint, tc.unchanged)
+		}
+	}
+}
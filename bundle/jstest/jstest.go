@@ -0,0 +1,455 @@
+// Package jstest runs the JavaScript policies of a bundle outside of Apigee
+// Edge, so that their behavior can be checked by ordinary Go tests.
+//
+// A Script is loaded from a bundle by policy name and run against a Context,
+// a mock of the Apigee JavaScript object model. After the run, the flow
+// variables, headers, content and status of the Context reflect what the
+// script did:
+//
+//	s, e := jstest.Load("apiproxy", "JS-InsertResponseHeader")
+//	...
+//	c := jstest.NewContext(jstest.ProxyResponseFlow)
+//	c.Variables["system.region.name"] = "us-west1"
+//	if e := s.Run(c); e != nil {
+//		...
+//	}
+//	if got := c.Response.Headers.Get("Region-Name"); got != "us-west1" {
+//		...
+//	}
+//
+// Only the commonly used parts of the object model are mocked: the context
+// object with getVariable, setVariable and removeVariable, the request and
+// response messages, the properties of the policy, and print. There is a
+// single request message and a single response message, so proxyRequest and
+// targetRequest are the same object, as are proxyResponse and targetResponse.
+// Scripts that use httpClient, crypto or other objects fail with a
+// ReferenceError.
+package jstest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/brayanhenao/go-apigee-edge/bundle"
+	"github.com/robertkrimen/otto"
+)
+
+// The values of context.flow, selecting the flow a script runs in.
+const (
+	ProxyRequestFlow   = "PROXY_REQ_FLOW"
+	ProxyResponseFlow  = "PROXY_RESP_FLOW"
+	TargetRequestFlow  = "TARGET_REQ_FLOW"
+	TargetResponseFlow = "TARGET_RESP_FLOW"
+)
+
+// Message is a mock HTTP request or response. Method, URL and QueryParams apply
+// to requests and StatusCode to responses.
+type Message struct {
+	Method      string
+	URL         string
+	QueryParams url.Values
+	StatusCode  int
+	Headers     http.Header
+	Content     string
+}
+
+func newMessage() *Message {
+	return &Message{QueryParams: url.Values{}, Headers: http.Header{}}
+}
+
+// Context is the state a script runs against. Variables holds the flow
+// variables other than those of the request and response messages, like
+// "proxy.pathsuffix" or "system.region.name". Values set by a script are
+// stored as exported by the JavaScript engine: strings, bools, float64 or
+// int64 numbers, and maps or slices for objects and arrays.
+type Context struct {
+	Flow      string
+	Variables map[string]interface{}
+	Request   *Message
+	Response  *Message
+
+	// Optional. Where the output of print is written. Discarded by default.
+	Output io.Writer
+}
+
+// NewContext returns an empty Context for the given flow. The request is a GET
+// and the response has status 200.
+func NewContext(flow string) *Context {
+	c := &Context{
+		Flow:      flow,
+		Variables: map[string]interface{}{},
+		Request:   newMessage(),
+		Response:  newMessage(),
+	}
+	c.Request.Method = "GET"
+	c.Response.StatusCode = http.StatusOK
+	return c
+}
+
+// source is one file of JavaScript run by a policy.
+type source struct {
+	name    string
+	content string
+}
+
+// Script is a Javascript policy together with the resources it runs.
+type Script struct {
+	Policy *bundle.Policy
+
+	// The properties of the policy, available to the script as properties.name.
+	Properties map[string]string
+
+	sources []source
+}
+
+// javascriptPolicy holds the parts of a Javascript policy not parsed by bundle.Policy.
+type javascriptPolicy struct {
+	Properties []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"Properties>Property"`
+	Source string `xml:"Source"`
+}
+
+// Load reads the bundle in source, a directory or zip file, and returns the
+// Javascript policy with the given name.
+func Load(source, policyName string) (*Script, error) {
+	b, e := bundle.Load(source)
+	if e != nil {
+		return nil, e
+	}
+	return New(b, policyName)
+}
+
+// New returns the Javascript policy of b with the given name. The resources of
+// the policy are read from the bundle and checked for syntax errors.
+func New(b *bundle.Bundle, policyName string) (*Script, error) {
+	p := b.Policy(policyName)
+	if p == nil {
+		return nil, fmt.Errorf("no policy %s in bundle %s", policyName, b.Name())
+	}
+	if p.Type() != "Javascript" {
+		return nil, fmt.Errorf("policy %s is of type %s, not Javascript", policyName, p.Type())
+	}
+	js := javascriptPolicy{}
+	if e := xml.Unmarshal(p.Content, &js); e != nil {
+		return nil, fmt.Errorf("while parsing policy %s, error: %v", policyName, e)
+	}
+
+	s := &Script{Policy: p, Properties: map[string]string{}}
+	for _, prop := range js.Properties {
+		s.Properties[prop.Name] = prop.Value
+	}
+	// Included resources run first, as they do in Apigee Edge.
+	for _, u := range p.IncludeURLs {
+		r := b.Resource(u)
+		if r == nil {
+			return nil, fmt.Errorf("policy %s includes %s, which is not in the bundle", policyName, u)
+		}
+		s.sources = append(s.sources, source{u, string(r.Content)})
+	}
+	switch {
+	case p.ResourceURL != "":
+		r := b.Resource(p.ResourceURL)
+		if r == nil {
+			return nil, fmt.Errorf("policy %s refers to %s, which is not in the bundle", policyName, p.ResourceURL)
+		}
+		s.sources = append(s.sources, source{p.ResourceURL, string(r.Content)})
+	case strings.TrimSpace(js.Source) != "":
+		s.sources = append(s.sources, source{policyName, js.Source})
+	default:
+		return nil, fmt.Errorf("policy %s has neither a ResourceURL nor a Source", policyName)
+	}
+
+	vm := otto.New()
+	for _, src := range s.sources {
+		if _, e := vm.Compile(src.name, src.content); e != nil {
+			return nil, fmt.Errorf("while compiling %s, error: %v", src.name, e)
+		}
+	}
+	return s, nil
+}
+
+// Run runs the script against c in a new JavaScript engine. The changes the
+// script makes to variables and messages are applied to c even if the script
+// throws, in which case the error is returned.
+func (s *Script) Run(c *Context) error {
+	vm := otto.New()
+	r := &run{vm: vm, ctx: c}
+	var e error
+	if r.request, e = r.messageObject(c.Request, true); e != nil {
+		return e
+	}
+	if r.response, e = r.messageObject(c.Response, false); e != nil {
+		return e
+	}
+	if e := r.install(s.Properties); e != nil {
+		return e
+	}
+
+	var runError error
+	for _, src := range s.sources {
+		if _, e := vm.Run(src.content); e != nil {
+			if jsError, ok := e.(*otto.Error); ok {
+				runError = fmt.Errorf("while running %s, error: %s", src.name, jsError.String())
+			} else {
+				runError = fmt.Errorf("while running %s, error: %v", src.name, e)
+			}
+			break
+		}
+	}
+	if e := r.sync(r.request, c.Request, true); e != nil {
+		return e
+	}
+	if e := r.sync(r.response, c.Response, false); e != nil {
+		return e
+	}
+	return runError
+}
+
+// run holds the state of a single Run. While the script runs, the JavaScript
+// message objects are the only copy of the messages; they are copied back to
+// the Context when it finishes.
+type run struct {
+	vm       *otto.Otto
+	ctx      *Context
+	request  *otto.Object
+	response *otto.Object
+}
+
+func (r *run) messageObject(m *Message, isRequest bool) (*otto.Object, error) {
+	obj, e := r.vm.Object("({headers: {}})")
+	if e != nil {
+		return nil, e
+	}
+	headers := r.objectProperty(obj, "headers")
+	for name, values := range m.Headers {
+		headers.Set(name, strings.Join(values, ","))
+	}
+	obj.Set("content", m.Content)
+	if isRequest {
+		obj.Set("method", m.Method)
+		obj.Set("url", m.URL)
+		queryParams, e := r.vm.Object("({})")
+		if e != nil {
+			return nil, e
+		}
+		for name, values := range m.QueryParams {
+			queryParams.Set(name, strings.Join(values, ","))
+		}
+		obj.Set("queryParams", queryParams)
+	} else {
+		obj.Set("status", m.StatusCode)
+	}
+	return obj, nil
+}
+
+// sync copies a JavaScript message object back to m.
+func (r *run) sync(obj *otto.Object, m *Message, isRequest bool) error {
+	m.Headers = http.Header{}
+	headers := r.objectProperty(obj, "headers")
+	for _, name := range headers.Keys() {
+		v, _ := headers.Get(name)
+		if !v.IsUndefined() && !v.IsNull() {
+			m.Headers.Set(name, v.String())
+		}
+	}
+	content, _ := obj.Get("content")
+	m.Content = stringValue(content)
+	if isRequest {
+		method, _ := obj.Get("method")
+		m.Method = stringValue(method)
+		u, _ := obj.Get("url")
+		m.URL = stringValue(u)
+		m.QueryParams = url.Values{}
+		queryParams := r.objectProperty(obj, "queryParams")
+		for _, name := range queryParams.Keys() {
+			v, _ := queryParams.Get(name)
+			if !v.IsUndefined() && !v.IsNull() {
+				m.QueryParams.Set(name, v.String())
+			}
+		}
+		return nil
+	}
+	status, _ := obj.Get("status")
+	code, e := strconv.Atoi(status.String())
+	if e != nil {
+		return fmt.Errorf("response status %q is not a number", status.String())
+	}
+	m.StatusCode = code
+	return nil
+}
+
+// objectProperty returns the object held in a property of obj, replacing the
+// property with an empty object if a script set it to something else.
+func (r *run) objectProperty(obj *otto.Object, name string) *otto.Object {
+	v, _ := obj.Get(name)
+	if v.IsObject() {
+		return v.Object()
+	}
+	empty, _ := r.vm.Object("({})")
+	obj.Set(name, empty)
+	return empty
+}
+
+func stringValue(v otto.Value) string {
+	if v.IsUndefined() || v.IsNull() {
+		return ""
+	}
+	return v.String()
+}
+
+// install defines the global objects available to a script.
+func (r *run) install(properties map[string]string) error {
+	context, e := r.vm.Object("({})")
+	if e != nil {
+		return e
+	}
+	context.Set("flow", r.ctx.Flow)
+	context.Set("getVariable", r.getVariable)
+	context.Set("setVariable", r.setVariable)
+	context.Set("removeVariable", r.removeVariable)
+	for _, name := range []string{"request", "proxyRequest", "targetRequest"} {
+		context.Set(name, r.request)
+	}
+	for _, name := range []string{"response", "proxyResponse", "targetResponse"} {
+		context.Set(name, r.response)
+	}
+
+	props, e := r.vm.Object("({})")
+	if e != nil {
+		return e
+	}
+	for name, value := range properties {
+		props.Set(name, value)
+	}
+
+	globals := map[string]interface{}{
+		"context":    context,
+		"request":    r.request,
+		"response":   r.response,
+		"properties": props,
+		"print":      r.print,
+	}
+	for name, value := range globals {
+		if e := r.vm.Set(name, value); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+func (r *run) print(call otto.FunctionCall) otto.Value {
+	if r.ctx.Output == nil {
+		return otto.UndefinedValue()
+	}
+	args := []string{}
+	for _, arg := range call.ArgumentList {
+		args = append(args, arg.String())
+	}
+	fmt.Fprintln(r.ctx.Output, strings.Join(args, " "))
+	return otto.UndefinedValue()
+}
+
+// messageVariable resolves a variable name like "response.header.DinoWasHere"
+// to the message object and the property holding it. The property object is
+// nil when the variable is held directly by the message, like "request.verb".
+// It returns false for variables that are not message variables.
+func (r *run) messageVariable(name string) (container *otto.Object, property string, ok bool) {
+	parts := strings.SplitN(name, ".", 3)
+	var obj *otto.Object
+	switch parts[0] {
+	case "request":
+		obj = r.request
+	case "response":
+		obj = r.response
+	case "message":
+		obj = r.request
+		if r.ctx.Flow == ProxyResponseFlow || r.ctx.Flow == TargetResponseFlow {
+			obj = r.response
+		}
+	default:
+		return nil, "", false
+	}
+	if len(parts) == 1 {
+		return nil, "", false
+	}
+	rest := strings.Join(parts[1:], ".")
+	switch {
+	case rest == "content":
+		return obj, "content", true
+	case rest == "verb" && obj == r.request:
+		return obj, "method", true
+	case rest == "url" && obj == r.request:
+		return obj, "url", true
+	case rest == "status.code" && obj == r.response:
+		return obj, "status", true
+	case parts[1] == "header" && len(parts) == 3:
+		headers := r.objectProperty(obj, "headers")
+		return headers, headerKey(headers, parts[2]), true
+	case parts[1] == "queryparam" && len(parts) == 3 && obj == r.request:
+		return r.objectProperty(obj, "queryParams"), parts[2], true
+	}
+	return nil, "", false
+}
+
+// headerKey returns the key under which the named header is held, ignoring
+// case, or name itself for a new header.
+func headerKey(headers *otto.Object, name string) string {
+	for _, key := range headers.Keys() {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return name
+}
+
+func (r *run) getVariable(call otto.FunctionCall) otto.Value {
+	name := call.Argument(0).String()
+	if obj, property, ok := r.messageVariable(name); ok {
+		v, _ := obj.Get(property)
+		if v.IsUndefined() {
+			return otto.NullValue()
+		}
+		return v
+	}
+	value, ok := r.ctx.Variables[name]
+	if !ok {
+		return otto.NullValue()
+	}
+	v, e := r.vm.ToValue(value)
+	if e != nil {
+		panic(r.vm.MakeTypeError(fmt.Sprintf("variable %s: %v", name, e)))
+	}
+	return v
+}
+
+func (r *run) setVariable(call otto.FunctionCall) otto.Value {
+	name := call.Argument(0).String()
+	value := call.Argument(1)
+	if obj, property, ok := r.messageVariable(name); ok {
+		obj.Set(property, value)
+		return otto.UndefinedValue()
+	}
+	exported, e := value.Export()
+	if e != nil {
+		panic(r.vm.MakeTypeError(fmt.Sprintf("variable %s: %v", name, e)))
+	}
+	r.ctx.Variables[name] = exported
+	return otto.UndefinedValue()
+}
+
+func (r *run) removeVariable(call otto.FunctionCall) otto.Value {
+	name := call.Argument(0).String()
+	if obj, property, ok := r.messageVariable(name); ok {
+		obj.Set(property, otto.UndefinedValue())
+		return otto.UndefinedValue()
+	}
+	delete(r.ctx.Variables, name)
+	return otto.UndefinedValue()
+}
@@ -0,0 +1,164 @@
+package jstest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/brayanhenao/go-apigee-edge/bundle"
+)
+
+func TestRunResourceTest(t *testing.T) {
+	s, e := Load("../../testdata/proxybundles/apiproxy-resourcetest1", "JS-InsertResponseHeader")
+	if e != nil {
+		t.Fatalf("while loading, error:\n%#v\n", e)
+	}
+	c := NewContext(ProxyResponseFlow)
+	c.Variables["system.region.name"] = "us-west1"
+	if e := s.Run(c); e != nil {
+		t.Fatalf("while running, error:\n%v\n", e)
+	}
+	if got := c.Response.Headers.Get("DinoWasHere"); got != "This is the OLD value" {
+		t.Errorf("DinoWasHere: got=%q", got)
+	}
+	if got := c.Response.Headers.Get("region-name"); got != "us-west1" {
+		t.Errorf("region-name: got=%q", got)
+	}
+}
+
+func parseForTesting(t *testing.T, policy, script string) *bundle.Bundle {
+	files := []*bundle.File{
+		{Path: "jstest.xml", Content: []byte(`<APIProxy name="jstest"/>`)},
+		{Path: "policies/JS-Test.xml", Content: []byte(policy)},
+		{Path: "policies/AM-Test.xml", Content: []byte(`<AssignMessage name="AM-Test"/>`)},
+		{Path: "resources/jsc/lib.js", Content: []byte(`function twice(s) { return s + s; }`)},
+	}
+	if script != "" {
+		files = append(files, &bundle.File{Path: "resources/jsc/test.js", Content: []byte(script)})
+	}
+	b, e := bundle.Parse(bundle.ProxyBundle, files)
+	if e != nil {
+		t.Fatalf("while parsing, error:\n%#v\n", e)
+	}
+	return b
+}
+
+const testPolicy = `<Javascript name="JS-Test">
+  <Properties><Property name="suffix">-done</Property></Properties>
+  <IncludeURL>jsc://lib.js</IncludeURL>
+  <ResourceURL>jsc://test.js</ResourceURL>
+</Javascript>`
+
+func TestRun(t *testing.T) {
+	tt := []struct {
+		desc   string
+		flow   string
+		script string
+		check  func(c *Context) string
+	}{
+		{
+			desc: "flow variables",
+			flow: ProxyRequestFlow,
+			script: `context.setVariable('out', twice(context.getVariable('in')) + properties.suffix);
+context.setVariable('flag', true);
+context.removeVariable('gone');
+context.setVariable('missing', context.getVariable('nothing') === null);`,
+			check: func(c *Context) string {
+				if c.Variables["out"] != "abab-done" || c.Variables["flag"] != true || c.Variables["missing"] != true {
+					return "variables not set"
+				}
+				if _, ok := c.Variables["gone"]; ok {
+					return "gone not removed"
+				}
+				return ""
+			},
+		},
+		{
+			desc: "request message",
+			flow: ProxyRequestFlow,
+			script: `context.proxyRequest.headers['X-Added'] = context.getVariable('request.header.x-in') + '!';
+context.setVariable('message.queryparam.q', request.queryParams.q.toUpperCase());
+context.setVariable('verb', context.getVariable('request.verb'));
+request.content = JSON.stringify({ok: true});
+context.targetRequest.method = 'POST';`,
+			check: func(c *Context) string {
+				if c.Request.Headers.Get("X-Added") != "hello!" || c.Request.QueryParams.Get("q") != "FIND" {
+					return "headers or query not set"
+				}
+				if c.Variables["verb"] != "GET" || c.Request.Method != "POST" || c.Request.Content != `{"ok":true}` {
+					return "verb or content wrong"
+				}
+				return ""
+			},
+		},
+		{
+			desc: "response message",
+			flow: TargetResponseFlow,
+			script: `var body = JSON.parse(context.getVariable('message.content'));
+context.setVariable('response.status.code', body.code);
+context.proxyResponse.headers['Content-Type'] = 'text/plain';
+print(context.flow, body.code);`,
+			check: func(c *Context) string {
+				if c.Response.StatusCode != 404 || c.Response.Headers.Get("Content-Type") != "text/plain" {
+					return "status or header not set"
+				}
+				if c.Output.(*bytes.Buffer).String() != "TARGET_RESP_FLOW 404\n" {
+					return "print output wrong"
+				}
+				return ""
+			},
+		},
+	}
+	for _, test := range tt {
+		s, e := New(parseForTesting(t, testPolicy, test.script), "JS-Test")
+		if e != nil {
+			t.Fatalf("%s: while loading, error:\n%v\n", test.desc, e)
+		}
+		c := NewContext(test.flow)
+		c.Output = new(bytes.Buffer)
+		c.Variables["in"] = "ab"
+		c.Variables["gone"] = 1
+		c.Request.Headers.Set("X-In", "hello")
+		c.Request.QueryParams.Set("q", "find")
+		c.Response.Content = `{"code": 404}`
+		if e := s.Run(c); e != nil {
+			t.Errorf("%s: while running, error:\n%v\n", test.desc, e)
+			continue
+		}
+		if problem := test.check(c); problem != "" {
+			t.Errorf("%s: %s: got=%#v", test.desc, problem, c)
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	s, e := New(parseForTesting(t, testPolicy, `context.setVariable('before', 1); throw new Error('boom');`), "JS-Test")
+	if e != nil {
+		t.Fatalf("while loading, error:\n%v\n", e)
+	}
+	c := NewContext(ProxyRequestFlow)
+	e = s.Run(c)
+	if e == nil || !strings.Contains(e.Error(), "boom") {
+		t.Errorf("got=%v, expected an error mentioning boom", e)
+	}
+	if _, ok := c.Variables["before"]; !ok {
+		t.Errorf("variable set before the error was lost")
+	}
+
+	tt := []struct {
+		desc   string
+		policy string
+		script string
+		name   string
+	}{
+		{"no such policy", testPolicy, "1;", "JS-Missing"},
+		{"not javascript", testPolicy, "1;", "AM-Test"},
+		{"missing resource", testPolicy, "", "JS-Test"},
+		{"syntax error", testPolicy, "var = ;", "JS-Test"},
+	}
+	for _, test := range tt {
+		if _, e := New(parseForTesting(t, test.policy, test.script), test.name); e == nil {
+			t.Errorf("%s: expected an error", test.desc)
+		}
+	}
+}
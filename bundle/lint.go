@@ -0,0 +1,256 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Severity indicates how serious a lint Issue is.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// The IDs of the rules checked by Lint.
+const (
+	RuleMissingPolicy         = "missing-policy"
+	RuleUnusedPolicy          = "unused-policy"
+	RuleMissingResource       = "missing-resource"
+	RuleDuplicateBasePath     = "duplicate-basepath"
+	RuleUnreachableRouteRule  = "unreachable-route-rule"
+	RuleMissingTargetEndpoint = "missing-target-endpoint"
+)
+
+// Issue is a problem found by Lint. Path is relative to the bundle root, and
+// Line is 1-based, or 0 when the problem applies to the file as a whole.
+type Issue struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"`
+	Line     int      `json:"line,omitempty"`
+	Message  string   `json:"message"`
+}
+
+func (i Issue) String() string {
+	location := i.Path
+	if i.Line > 0 {
+		location = fmt.Sprintf("%s:%d", i.Path, i.Line)
+	}
+	return fmt.Sprintf("%s: %s: %s (%s)", location, i.Severity, i.Message, i.Rule)
+}
+
+// LintDir loads the bundle at source, as for Load, and checks it with Lint.
+func LintDir(source string) ([]Issue, error) {
+	b, e := Load(source)
+	if e != nil {
+		return nil, e
+	}
+	return Lint(b), nil
+}
+
+// Lint checks a bundle for mistakes that would otherwise surface only after
+// import or at runtime. The issues are sorted by path and line. Resources
+// referenced by policies may legitimately be stored at the environment or
+// organization level, so missing resources are reported as warnings.
+func Lint(b *Bundle) []Issue {
+	l := &linter{bundle: b}
+	l.checkPolicyReferences()
+	l.checkResources()
+	l.checkBasePaths()
+	l.checkRouteRules()
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		a, b := l.issues[i], l.issues[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+	return l.issues
+}
+
+type linter struct {
+	bundle *Bundle
+	issues []Issue
+}
+
+func (l *linter) add(rule string, severity Severity, file File, line int, format string, args ...interface{}) {
+	l.issues = append(l.issues, Issue{
+		Rule:     rule,
+		Severity: severity,
+		Path:     file.Path,
+		Line:     line,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// stepSources pairs each file that contains steps with those steps.
+func (l *linter) stepSources() ([]File, [][]Step) {
+	files := []File{}
+	steps := [][]Step{}
+	for _, pe := range l.bundle.ProxyEndpoints {
+		files = append(files, pe.File)
+		steps = append(steps, pe.Steps())
+	}
+	for _, te := range l.bundle.TargetEndpoints {
+		files = append(files, te.File)
+		steps = append(steps, te.Steps())
+	}
+	for _, sf := range l.bundle.SharedFlows {
+		files = append(files, sf.File)
+		steps = append(steps, sf.Steps)
+	}
+	return files, steps
+}
+
+func (l *linter) checkPolicyReferences() {
+	referenced := map[string]bool{}
+	files, steps := l.stepSources()
+	for i, file := range files {
+		reported := map[string]bool{}
+		for _, step := range steps[i] {
+			referenced[step.Name] = true
+			if l.bundle.Policy(step.Name) != nil || reported[step.Name] {
+				continue
+			}
+			reported[step.Name] = true
+			l.add(RuleMissingPolicy, SeverityError, file, findElementLine(file.Content, "Name", step.Name),
+				"step refers to policy %q, which is not in the bundle", step.Name)
+		}
+	}
+	for _, p := range l.bundle.Policies {
+		if !referenced[p.Name] {
+			l.add(RuleUnusedPolicy, SeverityWarning, p.File, 0,
+				"policy %q is not referenced from any flow", p.Name)
+		}
+	}
+}
+
+func (l *linter) checkResources() {
+	for _, p := range l.bundle.Policies {
+		for _, url := range p.ResourceURLs() {
+			if l.bundle.Resource(url) != nil {
+				continue
+			}
+			line := findElementLine(p.Content, "ResourceURL", url)
+			if line == 0 {
+				line = findElementLine(p.Content, "IncludeURL", url)
+			}
+			l.add(RuleMissingResource, SeverityWarning, p.File, line,
+				"%s policy %q refers to resource %q, which is not in the bundle", p.Type(), p.Name, url)
+		}
+	}
+}
+
+func (l *linter) checkBasePaths() {
+	endpoints := l.bundle.ProxyEndpoints
+	for i := 0; i < len(endpoints); i++ {
+		for j := 0; j < i; j++ {
+			a, b := endpoints[i], endpoints[j]
+			if a.HTTPProxyConnection == nil || b.HTTPProxyConnection == nil {
+				continue
+			}
+			if cleanBasePath(a.BasePath()) != cleanBasePath(b.BasePath()) {
+				continue
+			}
+			if !shareVirtualHost(a.HTTPProxyConnection.VirtualHosts, b.HTTPProxyConnection.VirtualHosts) {
+				continue
+			}
+			l.add(RuleDuplicateBasePath, SeverityError, a.File, findElementLine(a.Content, "BasePath", a.BasePath()),
+				"proxy endpoint %q uses the same base path %q as %q", a.Name, a.BasePath(), b.Name)
+		}
+	}
+}
+
+func cleanBasePath(basePath string) string {
+	return path.Clean("/" + basePath)
+}
+
+// shareVirtualHost reports whether two endpoints listen on a common virtual host.
+// An endpoint that lists no virtual hosts listens on all of them.
+func shareVirtualHost(a, b []string) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return true
+	}
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (l *linter) checkRouteRules() {
+	for _, pe := range l.bundle.ProxyEndpoints {
+		unconditional := ""
+		for _, rule := range pe.RouteRules {
+			line := findElementLine(pe.Content, "RouteRule", rule.Name)
+			if unconditional != "" {
+				l.add(RuleUnreachableRouteRule, SeverityWarning, pe.File, line,
+					"route rule %q follows the unconditional route rule %q and will never be evaluated", rule.Name, unconditional)
+			} else if strings.TrimSpace(rule.Condition) == "" {
+				unconditional = rule.Name
+			}
+			if rule.TargetEndpoint != "" && l.bundle.TargetEndpoint(rule.TargetEndpoint) == nil {
+				l.add(RuleMissingTargetEndpoint, SeverityError, pe.File, findElementLine(pe.Content, "TargetEndpoint", rule.TargetEndpoint),
+					"route rule %q refers to target endpoint %q, which is not in the bundle", rule.Name, rule.TargetEndpoint)
+			}
+		}
+	}
+}
+
+// findElementLine returns the line of the first element with the given local
+// name whose name attribute or trimmed text content equals value. It returns 0
+// if there is no such element.
+func findElementLine(content []byte, element, value string) int {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	decoder.Strict = false
+	var candidate int64 = -1
+	var text bytes.Buffer
+	for {
+		offset := decoder.InputOffset()
+		token, e := decoder.Token()
+		if e != nil {
+			return 0
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local != element {
+				continue
+			}
+			for _, attr := range t.Attr {
+				if attr.Name.Local == "name" && attr.Value == value {
+					return lineAt(content, offset)
+				}
+			}
+			candidate = offset
+			text.Reset()
+		case xml.CharData:
+			if candidate >= 0 {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			if candidate >= 0 && t.Name.Local == element {
+				if strings.TrimSpace(text.String()) == value {
+					return lineAt(content, candidate)
+				}
+				candidate = -1
+			}
+		}
+	}
+}
+
+func lineAt(content []byte, offset int64) int {
+	// The offset precedes any whitespace before the element, so skip it.
+	for offset < int64(len(content)) && content[offset] != '<' {
+		offset++
+	}
+	return bytes.Count(content[:offset], []byte("\n")) + 1
+}
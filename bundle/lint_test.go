@@ -0,0 +1,106 @@
+package bundle
+
+import (
+	"testing"
+)
+
+const (
+	lintDescriptor = `<APIProxy name="linty"/>`
+
+	lintEndpoint1 = `<ProxyEndpoint name="endpoint1">
+    <HTTPProxyConnection>
+        <BasePath>/linty</BasePath>
+        <VirtualHost>secure</VirtualHost>
+    </HTTPProxyConnection>
+    <Flows>
+        <Flow name="f1">
+            <Request>
+                <Step>
+                    <Name>JS-Missing</Name>
+                </Step>
+                <Step>
+                    <Name>No-Such-Policy</Name>
+                </Step>
+            </Request>
+        </Flow>
+    </Flows>
+    <RouteRule name="default">
+        <TargetEndpoint>default</TargetEndpoint>
+    </RouteRule>
+    <RouteRule name="never">
+        <Condition>request.verb = "GET"</Condition>
+        <TargetEndpoint>nowhere</TargetEndpoint>
+    </RouteRule>
+</ProxyEndpoint>
+`
+
+	lintEndpoint2 = `<ProxyEndpoint name="endpoint2">
+    <HTTPProxyConnection>
+        <BasePath>/linty/</BasePath>
+    </HTTPProxyConnection>
+    <RouteRule name="noroute"/>
+</ProxyEndpoint>
+`
+
+	lintTarget = `<TargetEndpoint name="default">
+    <HTTPTargetConnection>
+        <URL>https://example.com</URL>
+    </HTTPTargetConnection>
+</TargetEndpoint>
+`
+
+	lintJsPolicy = `<Javascript name="JS-Missing">
+  <ResourceURL>jsc://missing.js</ResourceURL>
+  <IncludeURL>jsc://present.js</IncludeURL>
+</Javascript>
+`
+
+	lintUnusedPolicy = `<AssignMessage name="AM-Unused"/>`
+)
+
+func TestLintTestdata(t *testing.T) {
+	for _, source := range []string{libraryBundle, resourceBundle} {
+		issues, e := LintDir(source)
+		if e != nil {
+			t.Fatalf("while linting %s, error:\n%#v\n", source, e)
+		}
+		for _, issue := range issues {
+			t.Errorf("%s: unexpected issue: %s", source, issue)
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	b, e := Parse(ProxyBundle, []*File{
+		{Path: "linty.xml", Content: []byte(lintDescriptor)},
+		{Path: "proxies/endpoint1.xml", Content: []byte(lintEndpoint1)},
+		{Path: "proxies/endpoint2.xml", Content: []byte(lintEndpoint2)},
+		{Path: "targets/default.xml", Content: []byte(lintTarget)},
+		{Path: "policies/JS-Missing.xml", Content: []byte(lintJsPolicy)},
+		{Path: "policies/AM-Unused.xml", Content: []byte(lintUnusedPolicy)},
+		{Path: "resources/jsc/present.js", Content: []byte("// present")},
+	})
+	if e != nil {
+		t.Fatalf("while parsing, error:\n%#v\n", e)
+	}
+
+	expected := []Issue{
+		{Rule: RuleUnusedPolicy, Severity: SeverityWarning, Path: "policies/AM-Unused.xml"},
+		{Rule: RuleMissingResource, Severity: SeverityWarning, Path: "policies/JS-Missing.xml", Line: 2},
+		{Rule: RuleMissingPolicy, Severity: SeverityError, Path: "proxies/endpoint1.xml", Line: 13},
+		{Rule: RuleUnreachableRouteRule, Severity: SeverityWarning, Path: "proxies/endpoint1.xml", Line: 21},
+		{Rule: RuleMissingTargetEndpoint, Severity: SeverityError, Path: "proxies/endpoint1.xml", Line: 23},
+		{Rule: RuleDuplicateBasePath, Severity: SeverityError, Path: "proxies/endpoint2.xml", Line: 3},
+	}
+	issues := Lint(b)
+	if len(issues) != len(expected) {
+		t.Fatalf("issues: got=%v", issues)
+	}
+	for i, issue := range issues {
+		t.Logf("%s", issue)
+		want := expected[i]
+		if issue.Rule != want.Rule || issue.Severity != want.Severity || issue.Path != want.Path || issue.Line != want.Line {
+			t.Errorf("issue %d: got=%+v, expected=%+v", i, issue, want)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// OpenAPIOptions controls the proxy generated by FromOpenAPI.
+type OpenAPIOptions struct {
+	// Optional. The name of the proxy. Defaults to the title of the spec,
+	// lower-cased with runs of other characters replaced by a hyphen.
+	Name string
+
+	// Optional. The base path of the proxy. Defaults to the base path of the
+	// spec: basePath in OpenAPI 2, or the path of the first server in OpenAPI 3.
+	BasePath string
+
+	// Optional. The URL of the backend. Defaults to the host of the spec, or
+	// the first server. Ignored when TargetServer is set.
+	TargetURL string
+
+	// Optional. The name of a target server to route to, instead of a URL.
+	TargetServer string
+
+	// Optional. Require an API key, passed in the x-apikey header.
+	VerifyAPIKey bool
+
+	// Optional. When greater than 0, allow this many requests per minute. When
+	// VerifyAPIKey is also set, the quota of the API product takes precedence.
+	QuotaPerMinute int
+
+	// Optional. Answer CORS preflight requests, and add CORS headers to responses.
+	CORS bool
+}
+
+type openAPISpec struct {
+	Swagger string `yaml:"swagger" json:"swagger"`
+	OpenAPI string `yaml:"openapi" json:"openapi"`
+	Info    struct {
+		Title       string `yaml:"title" json:"title"`
+		Description string `yaml:"description" json:"description"`
+	} `yaml:"info" json:"info"`
+	Host     string   `yaml:"host" json:"host"`
+	BasePath string   `yaml:"basePath" json:"basePath"`
+	Schemes  []string `yaml:"schemes" json:"schemes"`
+	Servers  []struct {
+		URL string `yaml:"url" json:"url"`
+	} `yaml:"servers" json:"servers"`
+	Paths map[string]openAPIPathItem `yaml:"paths" json:"paths"`
+}
+
+type openAPIPathItem struct {
+	Get     *openAPIOperation `yaml:"get" json:"get"`
+	Put     *openAPIOperation `yaml:"put" json:"put"`
+	Post    *openAPIOperation `yaml:"post" json:"post"`
+	Delete  *openAPIOperation `yaml:"delete" json:"delete"`
+	Options *openAPIOperation `yaml:"options" json:"options"`
+	Head    *openAPIOperation `yaml:"head" json:"head"`
+	Patch   *openAPIOperation `yaml:"patch" json:"patch"`
+}
+
+type openAPIOperation struct {
+	OperationID string `yaml:"operationId" json:"operationId"`
+	Summary     string `yaml:"summary" json:"summary"`
+}
+
+// operations returns the operations of the path item by HTTP verb.
+func (item openAPIPathItem) operations() map[string]*openAPIOperation {
+	ops := map[string]*openAPIOperation{
+		"GET": item.Get, "PUT": item.Put, "POST": item.Post, "DELETE": item.Delete,
+		"OPTIONS": item.Options, "HEAD": item.Head, "PATCH": item.Patch,
+	}
+	for verb, op := range ops {
+		if op == nil {
+			delete(ops, verb)
+		}
+	}
+	return ops
+}
+
+// backend returns the base URL of the backend described by the spec.
+func (spec *openAPISpec) backend() string {
+	if len(spec.Servers) > 0 {
+		return spec.Servers[0].URL
+	}
+	if spec.Host == "" {
+		return ""
+	}
+	scheme := "https"
+	if len(spec.Schemes) > 0 && spec.Schemes[0] != "https" {
+		scheme = spec.Schemes[0]
+	}
+	return scheme + "://" + spec.Host + spec.BasePath
+}
+
+func (spec *openAPISpec) basePath() string {
+	if spec.BasePath != "" {
+		return spec.BasePath
+	}
+	if len(spec.Servers) > 0 {
+		if u, e := url.Parse(spec.Servers[0].URL); e == nil && u.Path != "" {
+			return u.Path
+		}
+	}
+	return "/"
+}
+
+var (
+	nonNameChars   = regexp.MustCompile(`[^A-Za-z0-9]+`)
+	pathParameters = regexp.MustCompile(`\{[^}]*\}`)
+)
+
+func slug(s string) string {
+	return strings.Trim(nonNameChars.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// parseOpenAPI parses an OpenAPI 2 or 3 document, in either YAML or JSON.
+func parseOpenAPI(content []byte) (*openAPISpec, error) {
+	spec := &openAPISpec{}
+	unmarshal := yaml.Unmarshal
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("{")) {
+		unmarshal = json.Unmarshal
+	}
+	if e := unmarshal(content, spec); e != nil {
+		return nil, e
+	}
+	if spec.Swagger == "" && spec.OpenAPI == "" {
+		return nil, errors.New("not an OpenAPI document: no swagger or openapi version")
+	}
+	if len(spec.Paths) == 0 {
+		return nil, errors.New("OpenAPI document has no paths")
+	}
+	return spec, nil
+}
+
+// FromOpenAPI reads the OpenAPI 2 or 3 document in specFile and generates a
+// pass-through API proxy for it, with a conditional flow for each operation. A
+// request that matches no operation is rejected with a 404. Write the result
+// with WriteDir or WriteZip to import it.
+func FromOpenAPI(specFile string, opts *OpenAPIOptions) (*Bundle, error) {
+	content, e := ioutil.ReadFile(specFile)
+	if e != nil {
+		return nil, e
+	}
+	spec, e := parseOpenAPI(content)
+	if e != nil {
+		return nil, fmt.Errorf("while reading %s, error: %v", specFile, e)
+	}
+	return generateProxy(spec, opts)
+}
+
+func generateProxy(spec *openAPISpec, opts *OpenAPIOptions) (*Bundle, error) {
+	if opts == nil {
+		opts = &OpenAPIOptions{}
+	}
+	name := opts.Name
+	if name == "" {
+		name = slug(spec.Info.Title)
+	}
+	if name == "" {
+		return nil, errors.New("no proxy name given, and the spec has no title")
+	}
+	basePath := opts.BasePath
+	if basePath == "" {
+		basePath = spec.basePath()
+	}
+	targetURL := opts.TargetURL
+	if targetURL == "" {
+		targetURL = spec.backend()
+	}
+	if targetURL == "" && opts.TargetServer == "" {
+		return nil, errors.New("no target URL given, and the spec names no server")
+	}
+
+	b := &Bundle{Kind: ProxyBundle}
+	// Preflight requests carry no API key and do not count against the quota.
+	securityCondition := ""
+	if opts.CORS {
+		securityCondition = "NOT (" + corsPreflight + ")"
+	}
+	addPolicy := func(name, content string) {
+		p, _ := ParsePolicy("policies/"+name+".xml", []byte(xmlHeader+content))
+		b.Policies = append(b.Policies, p)
+	}
+
+	proxy := &ProxyEndpoint{
+		File:                File{Path: "proxies/default.xml"},
+		Name:                "default",
+		PreFlow:             &Flow{Name: "PreFlow"},
+		PostFlow:            &Flow{Name: "PostFlow"},
+		HTTPProxyConnection: &HTTPProxyConnection{BasePath: basePath, VirtualHosts: []string{"secure"}},
+	}
+	if opts.CORS {
+		addPolicy("AM-AddCORS", corsPolicy)
+		proxy.Flows = append(proxy.Flows, Flow{
+			Name:      "PreflightOptions",
+			Condition: corsPreflight,
+			Response:  FlowPhase{Steps: []Step{{Name: "AM-AddCORS"}}},
+		})
+		proxy.PostFlow.Response.Steps = append(proxy.PostFlow.Response.Steps, Step{Name: "AM-AddCORS"})
+		proxy.RouteRules = append(proxy.RouteRules, RouteRule{
+			Name:      "NoRoute",
+			Condition: corsPreflight,
+		})
+	}
+	if opts.VerifyAPIKey {
+		addPolicy("VA-VerifyAPIKey", verifyAPIKeyPolicy)
+		proxy.PreFlow.Request.Steps = append(proxy.PreFlow.Request.Steps, Step{Name: "VA-VerifyAPIKey", Condition: securityCondition})
+	}
+	if opts.QuotaPerMinute > 0 {
+		countRef := ""
+		if opts.VerifyAPIKey {
+			countRef = ` countRef="verifyapikey.VA-VerifyAPIKey.apiproduct.developer.quota.limit"`
+		}
+		addPolicy("Q-Quota", fmt.Sprintf(quotaPolicy, opts.QuotaPerMinute, countRef))
+		proxy.PreFlow.Request.Steps = append(proxy.PreFlow.Request.Steps, Step{Name: "Q-Quota", Condition: securityCondition})
+	}
+
+	paths := []string{}
+	for p := range spec.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	flowNames := map[string]bool{}
+	for _, p := range paths {
+		ops := spec.Paths[p].operations()
+		verbs := []string{}
+		for verb := range ops {
+			verbs = append(verbs, verb)
+		}
+		sort.Strings(verbs)
+		for _, verb := range verbs {
+			op := ops[verb]
+			flowName := op.OperationID
+			if flowName == "" {
+				flowName = slug(verb + " " + p)
+			}
+			if flowNames[flowName] {
+				return nil, fmt.Errorf("duplicate operation %s", flowName)
+			}
+			flowNames[flowName] = true
+			proxy.Flows = append(proxy.Flows, Flow{
+				Name:        flowName,
+				Description: op.Summary,
+				Condition:   fmt.Sprintf(`(proxy.pathsuffix MatchesPath "%s") and (request.verb = "%s")`, pathParameters.ReplaceAllString(p, "*"), verb),
+			})
+		}
+	}
+	addPolicy("RF-UnknownResource", unknownResourcePolicy)
+	proxy.Flows = append(proxy.Flows, Flow{
+		Name:    "UnknownResource",
+		Request: FlowPhase{Steps: []Step{{Name: "RF-UnknownResource"}}},
+	})
+	proxy.RouteRules = append(proxy.RouteRules, RouteRule{Name: "default", TargetEndpoint: "default"})
+	b.ProxyEndpoints = []*ProxyEndpoint{proxy}
+
+	target := &TargetEndpoint{
+		File:                 File{Path: "targets/default.xml"},
+		Name:                 "default",
+		PreFlow:              &Flow{Name: "PreFlow"},
+		PostFlow:             &Flow{Name: "PostFlow"},
+		HTTPTargetConnection: &HTTPTargetConnection{URL: targetURL},
+	}
+	if opts.TargetServer != "" {
+		path := "/"
+		if u, e := url.Parse(targetURL); e == nil && u.Path != "" {
+			path = u.Path
+		}
+		target.HTTPTargetConnection = &HTTPTargetConnection{
+			LoadBalancer: &LoadBalancer{Servers: []LoadBalancerServer{{Name: opts.TargetServer}}},
+			Path:         path,
+		}
+	}
+	b.TargetEndpoints = []*TargetEndpoint{target}
+
+	b.Descriptor = &Descriptor{
+		File:            File{Path: name + ".xml"},
+		Name:            name,
+		Basepaths:       basePath,
+		Description:     spec.Info.Description,
+		DisplayName:     spec.Info.Title,
+		ProxyEndpoints:  []string{"default"},
+		TargetEndpoints: []string{"default"},
+	}
+	for _, p := range b.Policies {
+		b.Descriptor.Policies = append(b.Descriptor.Policies, p.Name)
+	}
+	sort.Strings(b.Descriptor.Policies)
+	return b, nil
+}
+
+const corsPreflight = `request.verb = "OPTIONS" AND request.header.origin != null AND request.header.access-control-request-method != null`
+
+const verifyAPIKeyPolicy = `<VerifyAPIKey name="VA-VerifyAPIKey">
+    <APIKey ref="request.header.x-apikey"/>
+</VerifyAPIKey>
+`
+
+const quotaPolicy = `<Quota name="Q-Quota">
+    <Allow count="%d"%s/>
+    <Interval>1</Interval>
+    <TimeUnit>minute</TimeUnit>
+    <Distributed>true</Distributed>
+    <Synchronous>false</Synchronous>
+</Quota>
+`
+
+const corsPolicy = `<AssignMessage name="AM-AddCORS">
+    <Set>
+        <Headers>
+            <Header name="Access-Control-Allow-Origin">{request.header.origin}</Header>
+            <Header name="Access-Control-Allow-Headers">origin, x-requested-with, accept, content-type, x-apikey</Header>
+            <Header name="Access-Control-Max-Age">3628800</Header>
+            <Header name="Access-Control-Allow-Methods">GET, PUT, POST, DELETE, PATCH</Header>
+        </Headers>
+    </Set>
+    <IgnoreUnresolvedVariables>true</IgnoreUnresolvedVariables>
+    <AssignTo createNew="false" transport="http" type="response"/>
+</AssignMessage>
+`
+
+const unknownResourcePolicy = `<RaiseFault name="RF-UnknownResource">
+    <FaultResponse>
+        <Set>
+            <StatusCode>404</StatusCode>
+            <ReasonPhrase>Not Found</ReasonPhrase>
+            <Payload contentType="application/json">{"error": "unknown resource"}</Payload>
+        </Set>
+    </FaultResponse>
+    <IgnoreUnresolvedVariables>true</IgnoreUnresolvedVariables>
+</RaiseFault>
+`
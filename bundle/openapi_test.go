@@ -0,0 +1,109 @@
+package bundle
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+const openAPIDir = "../testdata/openapi"
+
+func TestFromOpenAPI(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		spec             string
+		opts             *OpenAPIOptions
+		expectedBasePath string
+		expectedTarget   string
+		expectedFlows    []string
+		expectedPolicies int
+	}{
+		{
+			desc:             "swagger 2",
+			spec:             "petstore-v2.json",
+			expectedBasePath: "/v2",
+			expectedTarget:   "https://petstore.swagger.io/v2",
+			expectedFlows:    []string{"listPets", "createPet", "delete-pets-petid", "showPetById", "UnknownResource"},
+			expectedPolicies: 1,
+		},
+		{
+			desc:             "openapi 3 with security",
+			spec:             "petstore-v3.yaml",
+			opts:             &OpenAPIOptions{BasePath: "/pets/v1", VerifyAPIKey: true, QuotaPerMinute: 100, CORS: true},
+			expectedBasePath: "/pets/v1",
+			expectedTarget:   "https://petstore.example.com/v1",
+			expectedFlows:    []string{"PreflightOptions", "listPets", "createPet", "showPetById", "UnknownResource"},
+			expectedPolicies: 4,
+		},
+	}
+	for _, tc := range testCases {
+		b, e := FromOpenAPI(openAPIDir+"/"+tc.spec, tc.opts)
+		if e != nil {
+			t.Errorf("%s: while generating, error:\n%#v\n", tc.desc, e)
+			continue
+		}
+		if b.Name() != "swagger-petstore" {
+			t.Errorf("%s: name: got=%s", tc.desc, b.Name())
+		}
+
+		// Write the bundle out and read it back, as Import would.
+		tempDir, e := ioutil.TempDir("", "go-apigee-openapi-")
+		if e != nil {
+			t.Fatalf("while creating temp dir, error:\n%#v\n", e)
+		}
+		defer os.RemoveAll(tempDir)
+		if e := b.WriteDir(tempDir); e != nil {
+			t.Errorf("%s: while writing, error:\n%#v\n", tc.desc, e)
+			continue
+		}
+		b = loadForTesting(t, tempDir)
+
+		pe := b.ProxyEndpoint("default")
+		if pe == nil || pe.BasePath() != tc.expectedBasePath {
+			t.Errorf("%s: proxy endpoint: got=%#v", tc.desc, pe)
+			continue
+		}
+		flows := []string{}
+		for _, f := range pe.Flows {
+			flows = append(flows, f.Name)
+		}
+		if len(flows) != len(tc.expectedFlows) {
+			t.Errorf("%s: flows: got=%v, expected=%v", tc.desc, flows, tc.expectedFlows)
+		} else {
+			for i := range flows {
+				if flows[i] != tc.expectedFlows[i] {
+					t.Errorf("%s: flows: got=%v, expected=%v", tc.desc, flows, tc.expectedFlows)
+					break
+				}
+			}
+		}
+		te := b.TargetEndpoint("default")
+		if te == nil || te.HTTPTargetConnection.URL != tc.expectedTarget {
+			t.Errorf("%s: target endpoint: got=%#v", tc.desc, te)
+		}
+		if len(b.Policies) != tc.expectedPolicies || len(b.Descriptor.Policies) != tc.expectedPolicies {
+			t.Errorf("%s: policies: got=%d, expected=%d", tc.desc, len(b.Policies), tc.expectedPolicies)
+		}
+		if issues := Lint(b); len(issues) != 0 {
+			t.Errorf("%s: lint: got=%v", tc.desc, issues)
+		}
+	}
+}
+
+func TestFromOpenAPIFlowCondition(t *testing.T) {
+	b, e := FromOpenAPI(openAPIDir+"/petstore-v2.json", &OpenAPIOptions{Name: "pets", TargetServer: "petstore"})
+	if e != nil {
+		t.Fatalf("while generating, error:\n%#v\n", e)
+	}
+	expected := `(proxy.pathsuffix MatchesPath "/pets/*") and (request.verb = "GET")`
+	if got := b.ProxyEndpoints[0].Flows[3].Condition; got != expected {
+		t.Errorf("condition: got=%s, expected=%s", got, expected)
+	}
+	hc := b.TargetEndpoints[0].HTTPTargetConnection
+	if hc.URL != "" || hc.LoadBalancer == nil || hc.LoadBalancer.Servers[0].Name != "petstore" || hc.Path != "/v2" {
+		t.Errorf("target connection: got=%#v", hc)
+	}
+	if refs := b.References(); len(refs.TargetServers) != 1 {
+		t.Errorf("references: got=%#v", refs)
+	}
+}
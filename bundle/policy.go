@@ -0,0 +1,99 @@
+package bundle
+
+import (
+	"encoding/xml"
+	"path"
+	"strings"
+)
+
+// Policy is a file in the policies directory of a bundle. The XML element name
+// of the root is the policy type. Only the settings that tie a policy to other
+// parts of the bundle are parsed; the full configuration remains in Content.
+type Policy struct {
+	File            `xml:"-"`
+	XMLName         xml.Name
+	Name            string     `xml:"name,attr"`
+	Async           string     `xml:"async,attr,omitempty"`
+	ContinueOnError string     `xml:"continueOnError,attr,omitempty"`
+	Enabled         string     `xml:"enabled,attr,omitempty"`
+	OtherAttrs      []xml.Attr `xml:",any,attr"`
+	DisplayName     string     `xml:"DisplayName,omitempty"`
+
+	// ResourceURL is set for policies that execute a resource, like
+	// Javascript, XSL, JavaCallout and Script.
+	ResourceURL string `xml:"ResourceURL,omitempty"`
+
+	// IncludeURLs lists additional resources loaded by a Javascript policy.
+	IncludeURLs []string `xml:"IncludeURL"`
+
+	// SharedFlowBundle is set for FlowCallout policies.
+	SharedFlowBundle string `xml:"SharedFlowBundle,omitempty"`
+}
+
+// ParsePolicy parses the policy held in content.
+func ParsePolicy(path string, content []byte) (*Policy, error) {
+	p := &Policy{File: File{Path: path, Content: content}}
+	if e := xml.Unmarshal(content, p); e != nil {
+		return nil, e
+	}
+	return p, nil
+}
+
+// Type returns the policy type, eg "AssignMessage".
+func (p *Policy) Type() string {
+	return p.XMLName.Local
+}
+
+// ResourceURLs returns every resource URL referenced by the policy.
+func (p *Policy) ResourceURLs() []string {
+	urls := []string{}
+	if p.ResourceURL != "" {
+		urls = append(urls, p.ResourceURL)
+	}
+	return append(urls, p.IncludeURLs...)
+}
+
+// Resource is a file under the resources directory of a bundle, eg
+// resources/jsc/insertResponseHeader.js. Type is the name of the directory
+// immediately below resources, like "jsc", "xsl", "java", "node" or "py".
+type Resource struct {
+	File
+	Type string
+	Name string
+}
+
+func newResource(f *File) *Resource {
+	rest := strings.TrimPrefix(f.Path, "resources/")
+	parts := strings.SplitN(rest, "/", 2)
+	r := &Resource{File: File{Path: f.Path, Content: f.Content}, Type: parts[0]}
+	if len(parts) == 2 {
+		r.Name = parts[1]
+	}
+	return r
+}
+
+// NewResource returns a resource of the given type and name, eg "jsc" and
+// "insertResponseHeader.js".
+func NewResource(resourceType, name string, content []byte) *Resource {
+	return &Resource{
+		File: File{Path: path.Join("resources", resourceType, name), Content: content},
+		Type: resourceType,
+		Name: name,
+	}
+}
+
+// URL returns the URL used to refer to the resource from policies and from
+// the descriptor, eg "jsc://insertResponseHeader.js".
+func (r *Resource) URL() string {
+	return r.Type + "://" + r.Name
+}
+
+// ParseResourceURL splits a URL like "jsc://insertResponseHeader.js" into
+// its type and name. It returns false if url is not of that form.
+func ParseResourceURL(url string) (resourceType, name string, ok bool) {
+	parts := strings.SplitN(url, "://", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
@@ -0,0 +1,55 @@
+package bundle
+
+import (
+	"sort"
+	"strings"
+)
+
+// References lists the entities outside a bundle that it depends on at runtime.
+// Each list is sorted and free of duplicates.
+type References struct {
+	// SharedFlows are called by FlowCallout policies.
+	SharedFlows []string
+	// TargetServers are named in the load balancers of target endpoints.
+	TargetServers []string
+	// Proxies are called through local target connections, for proxy chaining.
+	Proxies []string
+}
+
+// References returns the external references of the bundle. Every policy is
+// considered, whether or not a flow refers to it, so that the result errs on
+// the side of caution.
+func (b *Bundle) References() References {
+	sharedFlows := map[string]bool{}
+	for _, p := range b.Policies {
+		if p.Type() == "FlowCallout" && strings.TrimSpace(p.SharedFlowBundle) != "" {
+			sharedFlows[strings.TrimSpace(p.SharedFlowBundle)] = true
+		}
+	}
+	targetServers := map[string]bool{}
+	proxies := map[string]bool{}
+	for _, te := range b.TargetEndpoints {
+		if te.HTTPTargetConnection != nil && te.HTTPTargetConnection.LoadBalancer != nil {
+			for _, server := range te.HTTPTargetConnection.LoadBalancer.Servers {
+				targetServers[server.Name] = true
+			}
+		}
+		if te.LocalTargetConnection != nil && te.LocalTargetConnection.APIProxy != "" {
+			proxies[te.LocalTargetConnection.APIProxy] = true
+		}
+	}
+	return References{
+		SharedFlows:   sortedKeys(sharedFlows),
+		TargetServers: sortedKeys(targetServers),
+		Proxies:       sortedKeys(proxies),
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,34 @@
+package bundle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReferences(t *testing.T) {
+	files := []*File{
+		{Path: "chain.xml", Content: []byte(`<APIProxy name="chain"/>`)},
+		{Path: "policies/FC-Auth.xml", Content: []byte(`<FlowCallout name="FC-Auth"><SharedFlowBundle>auth</SharedFlowBundle></FlowCallout>`)},
+		{Path: "policies/FC-Log.xml", Content: []byte(`<FlowCallout name="FC-Log"><SharedFlowBundle> logging </SharedFlowBundle></FlowCallout>`)},
+		{Path: "policies/FC-Auth2.xml", Content: []byte(`<FlowCallout name="FC-Auth2"><SharedFlowBundle>auth</SharedFlowBundle></FlowCallout>`)},
+		{Path: "targets/backend.xml", Content: []byte(`<TargetEndpoint name="backend"><HTTPTargetConnection><LoadBalancer>
+  <Server name="ts-2"/><Server name="ts-1"/></LoadBalancer></HTTPTargetConnection></TargetEndpoint>`)},
+		{Path: "targets/local.xml", Content: []byte(`<TargetEndpoint name="local"><LocalTargetConnection><APIProxy>inner</APIProxy></LocalTargetConnection></TargetEndpoint>`)},
+	}
+	b, e := Parse(ProxyBundle, files)
+	if e != nil {
+		t.Fatalf("while parsing, error:\n%#v\n", e)
+	}
+	expected := References{
+		SharedFlows:   []string{"auth", "logging"},
+		TargetServers: []string{"ts-1", "ts-2"},
+		Proxies:       []string{"inner"},
+	}
+	if got := b.References(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got=%#v, expected=%#v", got, expected)
+	}
+
+	if got := loadForTesting(t, libraryBundle).References(); len(got.SharedFlows) != 0 || len(got.TargetServers) != 0 {
+		t.Errorf("library: got=%#v", got)
+	}
+}
@@ -0,0 +1,182 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Template specializes a bundle, typically for an environment, by rewriting
+// its XML files. The same source bundle can then be imported with different
+// target URLs, quotas or KVM names in each environment.
+type Template struct {
+	// Optional. When set, every XML file is executed as a text/template with
+	// Data as the data, so that an action like {{.TargetURL}} is replaced by
+	// the TargetURL field or map entry. A missing map entry is an error.
+	Data interface{}
+
+	// Optional. Values to set at XML paths, keyed by the file path relative to
+	// the bundle root, a colon, and a slash-separated element path from the
+	// root element. A final @name segment selects an attribute rather than
+	// the text of the element. Every matching element is set, and it is an
+	// error if none matches. For example:
+	//   "targets/default.xml:/TargetEndpoint/HTTPTargetConnection/URL"
+	//   "policies/Q-Quota.xml:/Quota/Allow/@count"
+	Replacements map[string]string
+}
+
+// ApplyTemplate returns a copy of the bundle with the template applied.
+func (b *Bundle) ApplyTemplate(t *Template) (*Bundle, error) {
+	files, e := b.Files()
+	if e != nil {
+		return nil, e
+	}
+	byPath := map[string]*File{}
+	for _, f := range files {
+		byPath[f.Path] = f
+		if t.Data == nil || !strings.HasSuffix(f.Path, ".xml") || !bytes.Contains(f.Content, []byte("{{")) {
+			continue
+		}
+		tmpl, e := template.New(f.Path).Option("missingkey=error").Parse(string(f.Content))
+		if e != nil {
+			return nil, e
+		}
+		buf := new(bytes.Buffer)
+		if e := tmpl.Execute(buf, t.Data); e != nil {
+			return nil, e
+		}
+		f.Content = buf.Bytes()
+	}
+
+	keys := []string{}
+	for key := range t.Replacements {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts := strings.SplitN(key, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("replacement %q is not of the form file:/element/path", key)
+		}
+		f, ok := byPath[parts[0]]
+		if !ok {
+			return nil, fmt.Errorf("replacement %q: no file %s in bundle", key, parts[0])
+		}
+		content, e := setXMLPath(f.Content, parts[1], t.Replacements[key])
+		if e != nil {
+			return nil, fmt.Errorf("replacement %q: %v", key, e)
+		}
+		f.Content = content
+	}
+	return Parse(b.Kind, files)
+}
+
+// splice replaces content[start:end] with text.
+type splice struct {
+	start, end int
+	text       []byte
+}
+
+// setXMLPath sets the text, or the attribute, selected by xmlPath in every
+// matching element, leaving the rest of the content byte-for-byte unchanged.
+func setXMLPath(content []byte, xmlPath, value string) ([]byte, error) {
+	segments := strings.Split(strings.Trim(xmlPath, "/"), "/")
+	attr := ""
+	if last := segments[len(segments)-1]; strings.HasPrefix(last, "@") {
+		attr = last[1:]
+		segments = segments[:len(segments)-1]
+	}
+	escaped := new(bytes.Buffer)
+	if e := xml.EscapeText(escaped, []byte(value)); e != nil {
+		return nil, e
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	stack := []string{}
+	// textStart is the offset just after the start tag of a matched element,
+	// or -1 when no element is matched.
+	textStart := -1
+	splices := []splice{}
+	for {
+		offset := int(decoder.InputOffset())
+		token, e := decoder.Token()
+		if e != nil {
+			if e == io.EOF {
+				break
+			}
+			return nil, e
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			if !equalPath(stack, segments) {
+				continue
+			}
+			end := int(decoder.InputOffset())
+			if attr != "" {
+				s, e := attributeSplice(content, offset, end, attr, escaped.Bytes())
+				if e != nil {
+					return nil, e
+				}
+				splices = append(splices, s)
+			} else {
+				textStart = end
+			}
+		case xml.EndElement:
+			if textStart >= 0 && equalPath(stack, segments) {
+				if bytes.HasSuffix(content[:textStart], []byte("/>")) {
+					// A self-closing element, like <URL/>, must be opened up.
+					text := []byte(">" + escaped.String() + "</" + t.Name.Local + ">")
+					splices = append(splices, splice{textStart - 2, textStart, text})
+				} else {
+					splices = append(splices, splice{textStart, offset, escaped.Bytes()})
+				}
+				textStart = -1
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(splices) == 0 {
+		return nil, fmt.Errorf("no element matches %s", xmlPath)
+	}
+
+	out := new(bytes.Buffer)
+	last := 0
+	for _, s := range splices {
+		out.Write(content[last:s.start])
+		out.Write(s.text)
+		last = s.end
+	}
+	out.Write(content[last:])
+	return out.Bytes(), nil
+}
+
+func equalPath(stack, segments []string) bool {
+	if len(stack) != len(segments) {
+		return false
+	}
+	for i := range stack {
+		if stack[i] != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// attributeSplice locates the value of the named attribute within the start
+// tag at content[start:end].
+func attributeSplice(content []byte, start, end int, name string, value []byte) (splice, error) {
+	pattern := regexp.MustCompile(`\s` + regexp.QuoteMeta(name) + `\s*=\s*("[^"]*"|'[^']*')`)
+	loc := pattern.FindSubmatchIndex(content[start:end])
+	if loc == nil {
+		return splice{}, fmt.Errorf("no attribute %s", name)
+	}
+	// Replace the value between the quotes. The value is escaped, so it
+	// contains no quotes of either kind.
+	return splice{start + loc[2] + 1, start + loc[3] - 1, value}, nil
+}
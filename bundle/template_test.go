@@ -0,0 +1,92 @@
+package bundle
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetXMLPath(t *testing.T) {
+	content := `<?xml version="1.0"?>
+<TargetEndpoint name="default">
+    <!-- the backend -->
+    <HTTPTargetConnection>
+        <URL>https://test.example.com</URL>
+        <Path/>
+    </HTTPTargetConnection>
+    <Flows>
+        <Flow name="a"><Condition>x</Condition></Flow>
+        <Flow name='b'><Condition>y</Condition></Flow>
+    </Flows>
+</TargetEndpoint>`
+
+	testCases := []struct {
+		desc     string
+		path     string
+		value    string
+		expected string
+	}{
+		{"text", "/TargetEndpoint/HTTPTargetConnection/URL", "https://prod.example.com?a=1&b=2",
+			"<URL>https://prod.example.com?a=1&amp;b=2</URL>"},
+		{"self-closing", "/TargetEndpoint/HTTPTargetConnection/Path", "/v1",
+			"<Path>/v1</Path>"},
+		{"attribute", "/TargetEndpoint/@name", "prod",
+			`<TargetEndpoint name="prod">`},
+		{"every match", "/TargetEndpoint/Flows/Flow/Condition", "z",
+			`<Flow name="a"><Condition>z</Condition></Flow>
+        <Flow name='b'><Condition>z</Condition></Flow>`},
+		{"single-quoted attribute", "/TargetEndpoint/Flows/Flow/@name", `c"d`,
+			`<Flow name="c&#34;d"><Condition>x</Condition></Flow>
+        <Flow name='c&#34;d'>`},
+	}
+	for _, tc := range testCases {
+		actual, e := setXMLPath([]byte(content), tc.path, tc.value)
+		if e != nil {
+			t.Errorf("%s: error: %v", tc.desc, e)
+			continue
+		}
+		if !strings.Contains(string(actual), tc.expected) {
+			t.Errorf("%s: got=%s, expected to contain=%s", tc.desc, actual, tc.expected)
+		}
+		if !strings.Contains(string(actual), "<!-- the backend -->") {
+			t.Errorf("%s: comment was lost: %s", tc.desc, actual)
+		}
+	}
+
+	for _, path := range []string{"/TargetEndpoint/URL", "/TargetEndpoint/@missing"} {
+		if _, e := setXMLPath([]byte(content), path, "x"); e == nil {
+			t.Errorf("%s: expected an error", path)
+		}
+	}
+}
+
+func TestApplyTemplate(t *testing.T) {
+	original := loadForTesting(t, libraryBundle)
+	target := original.TargetEndpoint("library-soap")
+	target.Content = []byte(strings.Replace(string(target.Content),
+		"https://library-soap.herokuapp.com/Library", "{{.Backend}}/Library", 1))
+
+	tmpl := &Template{
+		Data: map[string]string{"Backend": "https://prod.example.com"},
+		Replacements: map[string]string{
+			"policies/Unknown-Resource.xml:/RaiseFault/FaultResponse/Set/ReasonPhrase": "Gone",
+		},
+	}
+	specialized, e := original.ApplyTemplate(tmpl)
+	if e != nil {
+		t.Fatalf("while applying template, error:\n%#v\n", e)
+	}
+	if got := specialized.TargetEndpoint("library-soap").HTTPTargetConnection.URL; got != "https://prod.example.com/Library" {
+		t.Errorf("target URL: got=%s", got)
+	}
+	if got := string(specialized.Policy("Unknown-Resource").Content); !strings.Contains(got, "<ReasonPhrase>Gone</ReasonPhrase>") {
+		t.Errorf("policy: got=%s", got)
+	}
+	if strings.Contains(string(original.Policy("Unknown-Resource").Content), "Gone") {
+		t.Errorf("original bundle was modified")
+	}
+
+	tmpl.Data = map[string]string{}
+	if _, e := original.ApplyTemplate(tmpl); e == nil {
+		t.Errorf("missing template value: expected an error")
+	}
+}
@@ -5,4 +5,7 @@ go 1.15
 require (
 	github.com/bgentry/go-netrc v0.0.0-20140422174119-9fd32a8b3d3d
 	github.com/google/go-querystring v1.0.0
+	github.com/robertkrimen/otto v0.0.0-20200922221731-ef014fd054ac
+	gopkg.in/sourcemap.v1 v1.0.5 // indirect
+	gopkg.in/yaml.v2 v2.4.0
 )